@@ -0,0 +1,70 @@
+package keyparsing
+
+// StripANSI returns b with ANSI escape sequences removed, leaving the visible
+// text. CSI sequences (cursor movement, SGR styles, erase), OSC and other
+// string sequences terminated by BEL or ST, and two-byte ESC sequences are
+// dropped. An incomplete sequence at the end of b is dropped as well. Other
+// bytes, including newlines and tabs, are kept.
+func StripANSI(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+
+	for i := 0; i < len(b); {
+		if b[i] != 0x1b {
+			out = append(out, b[i])
+			i++
+			continue
+		}
+
+		if i+1 >= len(b) {
+			break // Lone trailing ESC
+		}
+
+		switch b[i+1] {
+		case '[':
+			i = skipCSI(b, i+2)
+		case ']', 'P', 'X', '^', '_':
+			i = skipString(b, i+2)
+		default:
+			i = skipEscape(b, i+1)
+		}
+	}
+
+	return out
+}
+
+// skipCSI returns the index after the CSI sequence whose parameters start at i.
+func skipCSI(b []byte, i int) int {
+	for ; i < len(b); i++ {
+		// Parameter (0x30-0x3F) and intermediate (0x20-0x2F) bytes continue the
+		// sequence; anything else is the final byte.
+		if b[i] < 0x20 || b[i] > 0x3f {
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+// skipString returns the index after an OSC/DCS/SOS/PM/APC body starting at i,
+// which ends with BEL or ST (ESC \).
+func skipString(b []byte, i int) int {
+	for ; i < len(b); i++ {
+		switch {
+		case b[i] == 0x07:
+			return i + 1
+		case b[i] == 0x1b && i+1 < len(b) && b[i+1] == '\\':
+			return i + 2
+		}
+	}
+	return len(b)
+}
+
+// skipEscape returns the index after a non-CSI escape sequence whose first
+// byte after ESC is at i, such as ESC 7 or ESC ( B.
+func skipEscape(b []byte, i int) int {
+	for ; i < len(b); i++ {
+		if b[i] < 0x20 || b[i] > 0x2f {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,59 @@
+package keyparsing
+
+import (
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain text",
+			input:    "hello, 世界",
+			expected: "hello, 世界",
+		},
+		{
+			name: "rendered frame",
+			input: "\x1b[2J\x1b[1;1H\x1b[1;32m>>> \x1b[0mselect\x1b[K\r\n" +
+				"\x1b[?25l\x1b[38;5;208mwarning\x1b[m: done\x1b[?25h\x1b[3G",
+			expected: ">>> select\r\nwarning: done",
+		},
+		{
+			name:     "OSC title with BEL and ST",
+			input:    "\x1b]0;title\x07a\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\",
+			expected: "alink",
+		},
+		{
+			name:     "two-byte and charset escapes",
+			input:    "\x1b7saved\x1b8\x1b(Bascii",
+			expected: "savedascii",
+		},
+		{
+			name:     "incomplete CSI at end",
+			input:    "text\x1b[38;5",
+			expected: "text",
+		},
+		{
+			name:     "incomplete OSC at end",
+			input:    "text\x1b]0;unterminated",
+			expected: "text",
+		},
+		{
+			name:     "lone trailing escape",
+			input:    "text\x1b",
+			expected: "text",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := string(StripANSI([]byte(tc.input)))
+			if got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
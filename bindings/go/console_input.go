@@ -4,31 +4,27 @@ package keyparsing
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
-	"sync"
-	"syscall"
+	"io"
 	"time"
-
-	"golang.org/x/sys/unix"
 )
 
-// ConsoleInput provides cross-platform console input functionality
-// using the replkit Rust library through WASM runtime.
-type ConsoleInput struct {
-	keyParser   *KeyParser
-	fd          int
-	origTermios unix.Termios
-	inputChan   chan KeyEvent
-	sigChan     chan os.Signal
-	sizeChan    chan WindowSize
-	ctx         context.Context
-	cancel      context.CancelFunc
-	mu          sync.Mutex
-	rawMode     bool
-	running     bool
-}
+// escapeTimeout is how long an incomplete escape sequence may sit in the
+// parser before it is flushed, so a lone Escape key press is delivered.
+const escapeTimeout = 100 * time.Millisecond
+
+// pollInterval is how long readInput waits when no input is available.
+const pollInterval = 10 * time.Millisecond
+
+// cprTimeout bounds how long QueryCursorPosition waits for a terminal that
+// may never answer the query.
+const cprTimeout = 500 * time.Millisecond
+
+// ErrConsoleClosed is returned by ReadKeyContext and QueryCursorPosition once
+// the ConsoleInput has been closed. It is distinct from ErrClosed, which only
+// reports a closed KeyParser.
+var ErrConsoleClosed = errors.New("console input has been closed")
 
 // WindowSize represents terminal window dimensions.
 type WindowSize struct {
@@ -36,137 +32,19 @@ type WindowSize struct {
 	Rows    int
 }
 
-// NewConsoleInput creates a new ConsoleInput instance.
-func NewConsoleInput(ctx context.Context) (*ConsoleInput, error) {
-	parser, err := New(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create key parser: %w", err)
-	}
-
-	// Open /dev/tty for raw input like go-prompt does
-	fd, err := syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
-	if err != nil {
-		parser.Close()
-		return nil, fmt.Errorf("failed to open /dev/tty: %w", err)
-	}
-
-	inputCtx, cancel := context.WithCancel(ctx)
-	c := &ConsoleInput{
-		keyParser: parser,
-		fd:        fd,
-		inputChan: make(chan KeyEvent, 100),
-		sigChan:   make(chan os.Signal, 1),
-		sizeChan:  make(chan WindowSize, 1),
-		ctx:       inputCtx,
-		cancel:    cancel,
-	}
-
-	// Register signal handlers for window size changes
-	signal.Notify(c.sigChan, syscall.SIGWINCH)
-
-	// Start monitoring window size changes
-	go c.monitorWindowSize()
-
-	return c, nil
-}
-
-// EnableRawMode enables raw terminal mode using syscalls like go-prompt.
-func (c *ConsoleInput) EnableRawMode() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.rawMode {
-		return nil // Already in raw mode
-	}
-
-	// Save original termios
-	if err := c.getOriginalTermios(); err != nil {
-		return fmt.Errorf("failed to get original termios: %w", err)
-	}
-
-	// Set non-blocking mode
-	if err := syscall.SetNonblock(c.fd, true); err != nil {
-		return fmt.Errorf("failed to set non-blocking mode: %w", err)
-	}
-
-	// Set raw mode
-	if err := c.setRaw(); err != nil {
-		syscall.SetNonblock(c.fd, false) // Restore blocking mode on error
-		return fmt.Errorf("failed to set raw mode: %w", err)
-	}
-
-	c.rawMode = true
-
-	// Start reading input in a separate goroutine
-	go c.readInput()
-
-	return nil
-}
-
-// DisableRawMode disables raw terminal mode.
-func (c *ConsoleInput) DisableRawMode() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if !c.rawMode {
-		return nil // Not in raw mode
-	}
-
-	// Set blocking mode
-	if err := syscall.SetNonblock(c.fd, false); err != nil {
-		return fmt.Errorf("failed to set blocking mode: %w", err)
-	}
-
-	// Restore original termios
-	if err := c.restore(); err != nil {
-		return fmt.Errorf("failed to restore terminal mode: %w", err)
-	}
-
-	c.rawMode = false
-	return nil
-}
-
-// getOriginalTermios saves the original terminal settings
-func (c *ConsoleInput) getOriginalTermios() error {
-	termios, err := unix.IoctlGetTermios(c.fd, unix.TIOCGETA)
-	if err != nil {
-		return err
-	}
-	c.origTermios = *termios
-	return nil
-}
-
-// setRaw puts terminal into raw mode like go-prompt's SetRaw function
-func (c *ConsoleInput) setRaw() error {
-	termios := c.origTermios
-
-	// Disable input flags
-	termios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
-		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
-		syscall.ICRNL | syscall.IXON
-
-	// Disable local flags
-	termios.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN |
-		syscall.ISIG | syscall.ECHONL
-
-	// Disable control flags
-	termios.Cflag &^= syscall.CSIZE | syscall.PARENB
-	termios.Cflag |= syscall.CS8 // Set to 8-bit wide
-
-	// Set control characters
-	termios.Cc[syscall.VMIN] = 1
-	termios.Cc[syscall.VTIME] = 0
-
-	return unix.IoctlSetTermios(c.fd, unix.TIOCSETA, &termios)
-}
-
-// restore restores the original terminal settings
-func (c *ConsoleInput) restore() error {
-	return unix.IoctlSetTermios(c.fd, unix.TIOCSETA, &c.origTermios)
+// withClock replaces the time source used for timeouts. It is a test hook
+// and must be called before raw mode is enabled.
+func (c *ConsoleInput) withClock(clk clock) *ConsoleInput {
+	c.clock = clk
+	return c
 }
 
 // TryReadKey attempts to read a key without blocking.
 func (c *ConsoleInput) TryReadKey() (*KeyEvent, error) {
+	if event, ok := c.popDeferred(); ok {
+		return &event, nil
+	}
+
 	select {
 	case event := <-c.inputChan:
 		return &event, nil
@@ -177,6 +55,10 @@ func (c *ConsoleInput) TryReadKey() (*KeyEvent, error) {
 
 // ReadKey reads a key with an optional timeout.
 func (c *ConsoleInput) ReadKey(timeout time.Duration) (*KeyEvent, error) {
+	if event, ok := c.popDeferred(); ok {
+		return &event, nil
+	}
+
 	if timeout == 0 {
 		// Blocking read
 		select {
@@ -188,145 +70,142 @@ func (c *ConsoleInput) ReadKey(timeout time.Duration) (*KeyEvent, error) {
 	}
 
 	// Read with timeout
-	timer := time.NewTimer(timeout)
+	timer := c.clock.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
 	case event := <-c.inputChan:
 		return &event, nil
-	case <-timer.C:
+	case <-timer.C():
 		return nil, nil // Timeout
 	case <-c.ctx.Done():
 		return nil, c.ctx.Err()
 	}
 }
 
-// WindowSizeChanges returns a channel that receives window size changes.
-func (c *ConsoleInput) WindowSizeChanges() <-chan WindowSize {
-	return c.sizeChan
-}
-
-// GetWindowSize returns the current terminal window size using ioctl.
-func (c *ConsoleInput) GetWindowSize() (WindowSize, error) {
-	ws, err := unix.IoctlGetWinsize(c.fd, unix.TIOCGWINSZ)
-	if err != nil {
-		return WindowSize{}, fmt.Errorf("failed to get terminal size: %w", err)
+// ReadKeyContext blocks until a key is available or ctx is done, whichever
+// comes first. It returns ctx.Err() when ctx ends and ErrConsoleClosed once the
+// console input has been closed. Raw mode must be enabled for keys to arrive.
+func (c *ConsoleInput) ReadKeyContext(ctx context.Context) (KeyEvent, error) {
+	if event, ok := c.popDeferred(); ok {
+		return event, nil
 	}
-	return WindowSize{Columns: int(ws.Col), Rows: int(ws.Row)}, nil
-}
-
-// readInput reads raw input from the file descriptor and parses it into key events.
-func (c *ConsoleInput) readInput() {
-	c.mu.Lock()
-	c.running = true
-	c.mu.Unlock()
 
-	const maxReadBytes = 1024
-	buffer := make([]byte, maxReadBytes)
-
-	for {
-		select {
-		case <-c.ctx.Done():
-			return
-		default:
-			n, err := syscall.Read(c.fd, buffer)
-			if err != nil {
-				if err == syscall.EAGAIN || err == syscall.EWOULDBLOCK {
-					// No data available, sleep briefly and continue
-					time.Sleep(10 * time.Millisecond)
-					continue
-				}
-				// Other errors, continue reading
-				continue
-			}
-
-			if n > 0 {
-				// Parse the input bytes using KeyParser
-				input := buffer[:n]
-				events, err := c.keyParser.Feed(input)
-				if err != nil {
-					continue // Skip unparseable input
-				}
-
-				// Send all parsed events to the channel
-				for _, event := range events {
-					select {
-					case c.inputChan <- event:
-					case <-c.ctx.Done():
-						return
-					default:
-						// Channel is full, drop oldest events
-						select {
-						case <-c.inputChan:
-						default:
-						}
-						select {
-						case c.inputChan <- event:
-						case <-c.ctx.Done():
-							return
-						}
-					}
-				}
-			}
+	select {
+	case event, ok := <-c.inputChan:
+		if !ok {
+			return KeyEvent{}, ErrConsoleClosed
 		}
+		return event, nil
+	case <-ctx.Done():
+		return KeyEvent{}, ctx.Err()
+	case <-c.ctx.Done():
+		return KeyEvent{}, ErrConsoleClosed
 	}
 }
 
-// monitorWindowSize monitors terminal window size changes.
-func (c *ConsoleInput) monitorWindowSize() {
-	// Send initial size
-	if size, err := c.GetWindowSize(); err == nil {
-		select {
-		case c.sizeChan <- size:
-		case <-c.ctx.Done():
-			return
-		}
+// QueryCursorPosition asks the terminal where the cursor is by writing a
+// Device Status Report request (ESC [ 6 n) to out, normally the terminal's
+// output, and waiting for the CPRResponse. Row and column are 1-based. Keys
+// read while waiting are held back and returned, in order, by the next
+// reads, so no other goroutine should read keys during the query. Since some
+// terminals never answer, it gives up after cprTimeout or when ctx ends. It
+// returns ErrConsoleClosed if the console input is closed while waiting.
+func (c *ConsoleInput) QueryCursorPosition(ctx context.Context, out io.Writer) (row, col int, err error) {
+	if _, err := io.WriteString(out, "\x1b[6n"); err != nil {
+		return 0, 0, fmt.Errorf("failed to write cursor position query: %w", err)
 	}
 
+	timer := c.clock.NewTimer(cprTimeout)
+	defer timer.Stop()
+
 	for {
 		select {
-		case <-c.sigChan:
-			if size, err := c.GetWindowSize(); err == nil {
-				select {
-				case c.sizeChan <- size:
-				case <-c.ctx.Done():
-					return
+		case event, ok := <-c.inputChan:
+			if !ok {
+				return 0, 0, ErrConsoleClosed
+			}
+			if event.Key == CPRResponse {
+				if row, col, ok := ParseCPR(event); ok {
+					return row, col, nil
 				}
 			}
+			c.mu.Lock()
+			c.deferred = append(c.deferred, event)
+			c.mu.Unlock()
+		case <-timer.C():
+			return 0, 0, fmt.Errorf("timed out waiting for cursor position report")
+		case <-ctx.Done():
+			return 0, 0, ctx.Err()
 		case <-c.ctx.Done():
-			return
+			return 0, 0, ErrConsoleClosed
 		}
 	}
 }
 
-// Close cleans up resources and restores terminal state.
-func (c *ConsoleInput) Close() error {
-	c.cancel()
-
+// popDeferred returns the oldest key held back by QueryCursorPosition.
+func (c *ConsoleInput) popDeferred() (KeyEvent, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.rawMode {
-		// Restore terminal settings
-		syscall.SetNonblock(c.fd, false)
-		c.restore()
-		c.rawMode = false
+	if len(c.deferred) == 0 {
+		return KeyEvent{}, false
 	}
+	event := c.deferred[0]
+	c.deferred = c.deferred[1:]
+	return event, true
+}
 
-	// Close file descriptor
-	if c.fd != 0 {
-		syscall.Close(c.fd)
-	}
+// WindowSizeChanges returns a channel that receives window size changes.
+func (c *ConsoleInput) WindowSizeChanges() <-chan WindowSize {
+	return c.sizeChan
+}
 
-	signal.Stop(c.sigChan)
-	close(c.inputChan)
-	close(c.sizeChan)
+// idle is called by readInput when no input is available. Once the input
+// has been quiet for escapeTimeout it flushes any partial sequence left in
+// the parser (such as a lone Escape), then it waits for pollInterval.
+// It returns false once the console input has been closed.
+func (c *ConsoleInput) idle(pending *bool, lastInput time.Time) bool {
+	if *pending && c.clock.Now().Sub(lastInput) >= escapeTimeout {
+		*pending = false
+		if events, err := c.keyParser.Flush(); err == nil && !c.sendEvents(events) {
+			return false
+		}
+	}
 
-	if c.keyParser != nil {
-		return c.keyParser.Close()
+	timer := c.clock.NewTimer(pollInterval)
+	select {
+	case <-timer.C():
+		return true
+	case <-c.ctx.Done():
+		timer.Stop()
+		return false
 	}
+}
 
-	return nil
+// sendEvents delivers parsed events to the input channel, dropping the
+// oldest queued event when the channel is full. It returns false once the
+// console input has been closed.
+func (c *ConsoleInput) sendEvents(events []KeyEvent) bool {
+	for _, event := range events {
+		select {
+		case c.inputChan <- event:
+		case <-c.ctx.Done():
+			return false
+		default:
+			// Channel is full, drop oldest events
+			select {
+			case <-c.inputChan:
+			default:
+			}
+			select {
+			case c.inputChan <- event:
+			case <-c.ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // IsRawMode returns true if the terminal is in raw mode.
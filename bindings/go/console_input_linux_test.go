@@ -0,0 +1,136 @@
+//go:build linux
+
+package keyparsing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal pair, returning the master and slave.
+func openPTY() (master *os.File, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to unlock pty: %w", err)
+	}
+	n, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to get pty number: %w", err)
+	}
+
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+func TestConsoleInputReadKeyContextPTY(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("No PTY available: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	c, err := NewConsoleInputWithFd(context.Background(), int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("Failed to create console input: %v", err)
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			c.Close()
+		}
+	}()
+
+	if err := c.EnableRawMode(); err != nil {
+		t.Fatalf("Failed to enable raw mode: %v", err)
+	}
+
+	if _, err := master.Write([]byte("\x1b[Ax")); err != nil {
+		t.Fatalf("Failed to write to PTY: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, expected := range []Key{Up, NotDefined} {
+		event, err := c.ReadKeyContext(ctx)
+		if err != nil {
+			t.Fatalf("Failed to read key: %v", err)
+		}
+		if event.Key != expected {
+			t.Errorf("Expected %v, got: %v", expected, event.Key)
+		}
+	}
+
+	// A cancelled context ends the wait without a key
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := c.ReadKeyContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	// Closing the console input ends the wait as well
+	closed = true
+	if err := c.Close(); err != nil {
+		t.Fatalf("Failed to close console input: %v", err)
+	}
+	if _, err := c.ReadKeyContext(ctx); !errors.Is(err, ErrConsoleClosed) {
+		t.Errorf("Expected ErrConsoleClosed after Close, got: %v", err)
+	}
+}
+
+func TestConsoleInputQueryCursorPositionPTY(t *testing.T) {
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Skipf("No PTY available: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	c, err := NewConsoleInputWithFd(context.Background(), int(slave.Fd()))
+	if err != nil {
+		t.Fatalf("Failed to create console input: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.EnableRawMode(); err != nil {
+		t.Fatalf("Failed to enable raw mode: %v", err)
+	}
+
+	// Play the terminal: answer the query that arrives on the master side
+	go func() {
+		query := make([]byte, 4)
+		if _, err := io.ReadFull(master, query); err != nil || string(query) != "\x1b[6n" {
+			return
+		}
+		master.Write([]byte("\x1b[7;21R"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row, col, err := c.QueryCursorPosition(ctx, slave)
+	if err != nil {
+		t.Fatalf("Failed to query cursor position: %v", err)
+	}
+	if row != 7 || col != 21 {
+		t.Errorf("Expected (7, 21), got: (%d, %d)", row, col)
+	}
+}
@@ -0,0 +1,244 @@
+package keyparsing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic timeout tests.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	created chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Unix(0, 0),
+		created: make(chan struct{}, 100),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) timer {
+	c.mu.Lock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+
+	select {
+	case c.created <- struct{}{}:
+	default:
+	}
+	return t
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	return fakeTicker{t}
+}
+
+// Advance moves the clock forward and fires every timer that has expired.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	remaining := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			remaining = append(remaining, t)
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+		if t.period > 0 {
+			t.deadline = c.now.Add(t.period)
+			remaining = append(remaining, t)
+		}
+	}
+	c.timers = remaining
+}
+
+func (c *fakeClock) remove(target *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, t := range c.timers {
+		if t == target {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool { return t.clock.remove(t) }
+
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.ch }
+
+func (t fakeTicker) Stop() { t.t.clock.remove(t.t) }
+
+func TestConsoleInputReadKeyTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clk := newFakeClock()
+	c := (&ConsoleInput{
+		inputChan: make(chan KeyEvent, 1),
+		ctx:       ctx,
+	}).withClock(clk)
+
+	type result struct {
+		event *KeyEvent
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		event, err := c.ReadKey(time.Second)
+		done <- result{event, err}
+	}()
+
+	<-clk.created
+	clk.Advance(time.Second)
+
+	select {
+	case r := <-done:
+		if r.err != nil || r.event != nil {
+			t.Fatalf("Expected timeout with no event, got: %v, %v", r.event, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReadKey did not return after the fake clock advanced")
+	}
+}
+
+func TestConsoleInputReadKeyContextCancel(t *testing.T) {
+	consoleCtx, closeConsole := context.WithCancel(context.Background())
+	defer closeConsole()
+
+	c := &ConsoleInput{
+		inputChan: make(chan KeyEvent),
+		ctx:       consoleCtx,
+	}
+
+	readAsync := func(ctx context.Context) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.ReadKeyContext(ctx)
+			done <- err
+		}()
+		// Give the read time to block before the caller ends it
+		time.Sleep(10 * time.Millisecond)
+		return done
+	}
+	wait := func(done <-chan error) error {
+		t.Helper()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("ReadKeyContext did not return")
+			return nil
+		}
+	}
+
+	readCtx, cancel := context.WithCancel(context.Background())
+	done := readAsync(readCtx)
+	cancel()
+	if err := wait(done); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	done = readAsync(context.Background())
+	closeConsole()
+	if err := wait(done); !errors.Is(err, ErrConsoleClosed) {
+		t.Errorf("Expected ErrConsoleClosed, got: %v", err)
+	}
+}
+
+func TestConsoleInputQueryCursorPosition(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	text := "x"
+	c := (&ConsoleInput{
+		inputChan: make(chan KeyEvent, 10),
+		ctx:       ctx,
+	}).withClock(newFakeClock())
+
+	// A key typed before the terminal answers must not be lost
+	c.inputChan <- KeyEvent{Key: NotDefined, RawBytes: []byte(text), Text: &text}
+	c.inputChan <- KeyEvent{Key: CPRResponse, RawBytes: []byte("\x1b[12;34R")}
+
+	var out bytes.Buffer
+	row, col, err := c.QueryCursorPosition(ctx, &out)
+	if err != nil {
+		t.Fatalf("Failed to query cursor position: %v", err)
+	}
+	if row != 12 || col != 34 {
+		t.Errorf("Expected (12, 34), got: (%d, %d)", row, col)
+	}
+	if out.String() != "\x1b[6n" {
+		t.Errorf("Expected DSR query to be written, got: %q", out.String())
+	}
+
+	event, err := c.TryReadKey()
+	if err != nil || event == nil || event.Text == nil || *event.Text != "x" {
+		t.Errorf("Expected held-back key \"x\", got: %+v, %v", event, err)
+	}
+}
+
+func TestConsoleInputQueryCursorPositionTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clk := newFakeClock()
+	c := (&ConsoleInput{
+		inputChan: make(chan KeyEvent, 1),
+		ctx:       ctx,
+	}).withClock(clk)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := c.QueryCursorPosition(ctx, io.Discard)
+		done <- err
+	}()
+
+	<-clk.created
+	clk.Advance(cprTimeout)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Expected an error when the terminal does not answer")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("QueryCursorPosition did not return after the fake clock advanced")
+	}
+}
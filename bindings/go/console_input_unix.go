@@ -0,0 +1,301 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package keyparsing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ConsoleInput provides cross-platform console input functionality
+// using the replkit Rust library through WASM runtime.
+type ConsoleInput struct {
+	keyParser   *KeyParser
+	fd          int
+	ownsFd      bool
+	origTermios unix.Termios
+	inputChan   chan KeyEvent
+	sigChan     chan os.Signal
+	sizeChan    chan WindowSize
+	ctx         context.Context
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	rawMode     bool
+	running     bool
+	clock       clock
+	deferred    []KeyEvent // Keys held back by QueryCursorPosition
+}
+
+// NewConsoleInput creates a new ConsoleInput instance reading from /dev/tty.
+func NewConsoleInput(ctx context.Context) (*ConsoleInput, error) {
+	// Open /dev/tty for raw input like go-prompt does
+	fd, err := syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open /dev/tty: %w", err)
+	}
+
+	c, err := newConsoleInput(ctx, fd)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	c.ownsFd = true
+	return c, nil
+}
+
+// NewConsoleInputWithFd creates a ConsoleInput that reads from fd instead of
+// opening /dev/tty, e.g. the slave side of a PTY pair or a forwarded SSH
+// terminal. The caller keeps ownership of fd: Close restores the terminal
+// settings but does not close the descriptor, so it must stay open until
+// Close returns and be closed by the caller afterwards.
+func NewConsoleInputWithFd(ctx context.Context, fd int) (*ConsoleInput, error) {
+	if fd < 0 {
+		return nil, fmt.Errorf("invalid file descriptor: %d", fd)
+	}
+	return newConsoleInput(ctx, fd)
+}
+
+// newConsoleInput sets up a ConsoleInput around an open descriptor.
+func newConsoleInput(ctx context.Context, fd int) (*ConsoleInput, error) {
+	parser, err := New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key parser: %w", err)
+	}
+
+	inputCtx, cancel := context.WithCancel(ctx)
+	c := &ConsoleInput{
+		keyParser: parser,
+		fd:        fd,
+		inputChan: make(chan KeyEvent, 100),
+		sigChan:   make(chan os.Signal, 1),
+		sizeChan:  make(chan WindowSize, 1),
+		ctx:       inputCtx,
+		cancel:    cancel,
+		clock:     realClock{},
+	}
+
+	// Register signal handlers for window size changes
+	signal.Notify(c.sigChan, syscall.SIGWINCH)
+
+	// Start monitoring window size changes
+	go c.monitorWindowSize()
+
+	return c, nil
+}
+
+// EnableRawMode enables raw terminal mode using syscalls like go-prompt.
+func (c *ConsoleInput) EnableRawMode() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rawMode {
+		return nil // Already in raw mode
+	}
+
+	// Save original termios
+	if err := c.getOriginalTermios(); err != nil {
+		return fmt.Errorf("failed to get original termios: %w", err)
+	}
+
+	// Set non-blocking mode
+	if err := syscall.SetNonblock(c.fd, true); err != nil {
+		return fmt.Errorf("failed to set non-blocking mode: %w", err)
+	}
+
+	// Set raw mode
+	if err := c.setRaw(); err != nil {
+		syscall.SetNonblock(c.fd, false) // Restore blocking mode on error
+		return fmt.Errorf("failed to set raw mode: %w", err)
+	}
+
+	c.rawMode = true
+
+	// Start reading input in a separate goroutine
+	go c.readInput()
+
+	return nil
+}
+
+// DisableRawMode disables raw terminal mode.
+func (c *ConsoleInput) DisableRawMode() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.rawMode {
+		return nil // Not in raw mode
+	}
+
+	// Set blocking mode
+	if err := syscall.SetNonblock(c.fd, false); err != nil {
+		return fmt.Errorf("failed to set blocking mode: %w", err)
+	}
+
+	// Restore original termios
+	if err := c.restore(); err != nil {
+		return fmt.Errorf("failed to restore terminal mode: %w", err)
+	}
+
+	c.rawMode = false
+	return nil
+}
+
+// getOriginalTermios saves the original terminal settings
+func (c *ConsoleInput) getOriginalTermios() error {
+	termios, err := getTermios(c.fd)
+	if err != nil {
+		return err
+	}
+	c.origTermios = *termios
+	return nil
+}
+
+// setRaw puts terminal into raw mode like go-prompt's SetRaw function
+func (c *ConsoleInput) setRaw() error {
+	termios := c.origTermios
+
+	// Disable input flags
+	termios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
+		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
+		syscall.ICRNL | syscall.IXON
+
+	// Disable local flags
+	termios.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN |
+		syscall.ISIG | syscall.ECHONL
+
+	// Disable control flags
+	termios.Cflag &^= syscall.CSIZE | syscall.PARENB
+	termios.Cflag |= syscall.CS8 // Set to 8-bit wide
+
+	// Set control characters
+	termios.Cc[syscall.VMIN] = 1
+	termios.Cc[syscall.VTIME] = 0
+
+	return setTermios(c.fd, &termios)
+}
+
+// restore restores the original terminal settings
+func (c *ConsoleInput) restore() error {
+	return setTermios(c.fd, &c.origTermios)
+}
+
+// GetWindowSize returns the current terminal window size using ioctl.
+func (c *ConsoleInput) GetWindowSize() (WindowSize, error) {
+	ws, err := unix.IoctlGetWinsize(c.fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return WindowSize{}, fmt.Errorf("failed to get terminal size: %w", err)
+	}
+	return WindowSize{Columns: int(ws.Col), Rows: int(ws.Row)}, nil
+}
+
+// readInput reads raw input from the file descriptor and parses it into key events.
+func (c *ConsoleInput) readInput() {
+	c.mu.Lock()
+	c.running = true
+	c.mu.Unlock()
+
+	const maxReadBytes = 1024
+	buffer := make([]byte, maxReadBytes)
+
+	// pending is set while the parser may hold an incomplete sequence
+	pending := false
+	lastInput := c.clock.Now()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		default:
+			n, err := syscall.Read(c.fd, buffer)
+			if err != nil {
+				if err == syscall.EAGAIN || err == syscall.EWOULDBLOCK {
+					// No data available, wait briefly and continue
+					if !c.idle(&pending, lastInput) {
+						return
+					}
+					continue
+				}
+				// Other errors, continue reading
+				continue
+			}
+
+			if n > 0 {
+				pending = true
+				lastInput = c.clock.Now()
+
+				// Parse the input bytes using KeyParser
+				input := buffer[:n]
+				events, err := c.keyParser.Feed(input)
+				if err != nil {
+					continue // Skip unparseable input
+				}
+
+				if !c.sendEvents(events) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// monitorWindowSize monitors terminal window size changes.
+func (c *ConsoleInput) monitorWindowSize() {
+	// Send initial size
+	if size, err := c.GetWindowSize(); err == nil {
+		select {
+		case c.sizeChan <- size:
+		case <-c.ctx.Done():
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-c.sigChan:
+			if size, err := c.GetWindowSize(); err == nil {
+				select {
+				case c.sizeChan <- size:
+				case <-c.ctx.Done():
+					return
+				}
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// Close cleans up resources and restores terminal state.
+func (c *ConsoleInput) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rawMode {
+		// Restore terminal settings
+		syscall.SetNonblock(c.fd, false)
+		c.restore()
+		c.rawMode = false
+	}
+
+	// Close file descriptor unless the caller owns it
+	if c.ownsFd {
+		syscall.Close(c.fd)
+	}
+
+	signal.Stop(c.sigChan)
+	close(c.inputChan)
+	close(c.sizeChan)
+
+	if c.keyParser != nil {
+		return c.keyParser.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package keyparsing
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestConsoleInputEscapeTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	fd := int(r.Fd())
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		t.Fatalf("Failed to set non-blocking mode: %v", err)
+	}
+
+	clk := newFakeClock()
+	c := (&ConsoleInput{
+		keyParser: parser,
+		fd:        fd,
+		inputChan: make(chan KeyEvent, 10),
+		ctx:       ctx,
+	}).withClock(clk)
+	go c.readInput()
+
+	if _, err := w.Write([]byte{0x1b}); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	start := clk.Now()
+	for i := 0; i < 100; i++ {
+		select {
+		case event := <-c.inputChan:
+			if event.Key != Escape {
+				t.Fatalf("Expected Escape, got: %v", event.Key)
+			}
+			if elapsed := clk.Now().Sub(start); elapsed < escapeTimeout {
+				t.Fatalf("Escape delivered after %v, before the %v timeout", elapsed, escapeTimeout)
+			}
+			return
+		case <-clk.created:
+			clk.Advance(pollInterval)
+		case <-time.After(5 * time.Second):
+			t.Fatal("readInput stopped polling")
+		}
+	}
+	t.Fatal("Escape was never flushed")
+}
+
+func TestTermiosRoundTrip(t *testing.T) {
+	fd, err := syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
+	if err != nil {
+		t.Skipf("No TTY available: %v", err)
+	}
+	defer syscall.Close(fd)
+
+	termios, err := getTermios(fd)
+	if err != nil {
+		t.Fatalf("Failed to get termios: %v", err)
+	}
+
+	// Writing back the unchanged settings must succeed and be a no-op
+	if err := setTermios(fd, termios); err != nil {
+		t.Fatalf("Failed to set termios: %v", err)
+	}
+
+	after, err := getTermios(fd)
+	if err != nil {
+		t.Fatalf("Failed to get termios: %v", err)
+	}
+	if after.Lflag != termios.Lflag || after.Iflag != termios.Iflag {
+		t.Errorf("Expected termios flags to be unchanged, got: %+v", after)
+	}
+}
+
+func TestNewConsoleInputWithFdKeepsCallerFd(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	fd := int(r.Fd())
+	c, err := NewConsoleInputWithFd(context.Background(), fd)
+	if err != nil {
+		t.Fatalf("Failed to create console input: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Failed to close console input: %v", err)
+	}
+
+	// The descriptor belongs to the caller and must still be open
+	var stat syscall.Stat_t
+	if err := syscall.Fstat(fd, &stat); err != nil {
+		t.Errorf("Expected caller's fd to stay open after Close, got: %v", err)
+	}
+}
+
+func TestNewConsoleInputWithFdInvalid(t *testing.T) {
+	if _, err := NewConsoleInputWithFd(context.Background(), -1); err == nil {
+		t.Error("Expected error for negative file descriptor")
+	}
+}
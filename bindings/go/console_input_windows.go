@@ -0,0 +1,313 @@
+//go:build windows
+
+package keyparsing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                          = windows.NewLazySystemDLL("kernel32.dll")
+	procGetNumberOfConsoleInputEvents = kernel32.NewProc("GetNumberOfConsoleInputEvents")
+	procReadConsoleInputW             = kernel32.NewProc("ReadConsoleInputW")
+)
+
+// windowSizePollInterval is how often the console size is checked, since
+// Windows has no SIGWINCH equivalent.
+const windowSizePollInterval = 250 * time.Millisecond
+
+// maxInputRecords is the number of console input records read per call.
+const maxInputRecords = 128
+
+// inputRecord mirrors the Win32 INPUT_RECORD structure.
+type inputRecord struct {
+	eventType uint16
+	_         uint16
+	event     [16]byte
+}
+
+// keyEventRecord mirrors the Win32 KEY_EVENT_RECORD structure.
+type keyEventRecord struct {
+	keyDown         int32
+	repeatCount     uint16
+	virtualKeyCode  uint16
+	virtualScanCode uint16
+	unicodeChar     uint16
+	controlKeyState uint32
+}
+
+// ConsoleInput provides cross-platform console input functionality
+// using the replkit Rust library through WASM runtime.
+type ConsoleInput struct {
+	keyParser *KeyParser
+	in        windows.Handle
+	out       windows.Handle
+	origMode  uint32
+	inputChan chan KeyEvent
+	sizeChan  chan WindowSize
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	rawMode   bool
+	running   bool
+	clock     clock
+	deferred  []KeyEvent // Keys held back by QueryCursorPosition
+}
+
+// NewConsoleInput creates a new ConsoleInput instance.
+func NewConsoleInput(ctx context.Context) (*ConsoleInput, error) {
+	parser, err := New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key parser: %w", err)
+	}
+
+	// Open the console directly so redirected stdin/stdout do not matter
+	in, err := openConsole("CONIN$")
+	if err != nil {
+		parser.Close()
+		return nil, fmt.Errorf("failed to open CONIN$: %w", err)
+	}
+	out, err := openConsole("CONOUT$")
+	if err != nil {
+		windows.CloseHandle(in)
+		parser.Close()
+		return nil, fmt.Errorf("failed to open CONOUT$: %w", err)
+	}
+
+	inputCtx, cancel := context.WithCancel(ctx)
+	c := &ConsoleInput{
+		keyParser: parser,
+		in:        in,
+		out:       out,
+		inputChan: make(chan KeyEvent, 100),
+		sizeChan:  make(chan WindowSize, 1),
+		ctx:       inputCtx,
+		cancel:    cancel,
+		clock:     realClock{},
+	}
+
+	// Start monitoring window size changes
+	go c.monitorWindowSize()
+
+	return c, nil
+}
+
+// openConsole opens a console device such as CONIN$ or CONOUT$.
+func openConsole(name string) (windows.Handle, error) {
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	return windows.CreateFile(path,
+		windows.GENERIC_READ|windows.GENERIC_WRITE,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+		nil, windows.OPEN_EXISTING, 0, 0)
+}
+
+// EnableRawMode disables line buffering and echo on the console and asks
+// it to report special keys as VT sequences, which KeyParser understands.
+func (c *ConsoleInput) EnableRawMode() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rawMode {
+		return nil // Already in raw mode
+	}
+
+	// Save original console mode
+	if err := windows.GetConsoleMode(c.in, &c.origMode); err != nil {
+		return fmt.Errorf("failed to get console mode: %w", err)
+	}
+
+	mode := c.origMode
+	mode &^= windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT | windows.ENABLE_PROCESSED_INPUT
+	mode |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(c.in, mode); err != nil {
+		return fmt.Errorf("failed to set raw mode: %w", err)
+	}
+
+	c.rawMode = true
+
+	// Start reading input in a separate goroutine
+	go c.readInput()
+
+	return nil
+}
+
+// DisableRawMode restores the original console mode.
+func (c *ConsoleInput) DisableRawMode() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.rawMode {
+		return nil // Not in raw mode
+	}
+
+	if err := windows.SetConsoleMode(c.in, c.origMode); err != nil {
+		return fmt.Errorf("failed to restore console mode: %w", err)
+	}
+
+	c.rawMode = false
+	return nil
+}
+
+// GetWindowSize returns the current console window size.
+func (c *ConsoleInput) GetWindowSize() (WindowSize, error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(c.out, &info); err != nil {
+		return WindowSize{}, fmt.Errorf("failed to get terminal size: %w", err)
+	}
+	return WindowSize{
+		Columns: int(info.Window.Right-info.Window.Left) + 1,
+		Rows:    int(info.Window.Bottom-info.Window.Top) + 1,
+	}, nil
+}
+
+// readInput reads console input records and parses them into key events.
+// Only key-down records carry input; with ENABLE_VIRTUAL_TERMINAL_INPUT
+// special keys arrive as VT escape sequences in their characters.
+func (c *ConsoleInput) readInput() {
+	c.mu.Lock()
+	c.running = true
+	c.mu.Unlock()
+
+	records := make([]inputRecord, maxInputRecords)
+	var units []uint16
+
+	pending := false
+	lastInput := c.clock.Now()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		default:
+		}
+
+		var count uint32
+		r, _, _ := procGetNumberOfConsoleInputEvents.Call(uintptr(c.in), uintptr(unsafe.Pointer(&count)))
+		if r == 0 || count == 0 {
+			// No data available, wait briefly and continue
+			if !c.idle(&pending, lastInput) {
+				return
+			}
+			continue
+		}
+
+		var read uint32
+		r, _, _ = procReadConsoleInputW.Call(uintptr(c.in),
+			uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&read)))
+		if r == 0 {
+			continue
+		}
+
+		for _, record := range records[:read] {
+			if record.eventType != windows.KEY_EVENT {
+				continue
+			}
+			key := (*keyEventRecord)(unsafe.Pointer(&record.event[0]))
+			if key.keyDown == 0 || key.unicodeChar == 0 {
+				continue
+			}
+			for i := uint16(0); i < max(key.repeatCount, 1); i++ {
+				units = append(units, key.unicodeChar)
+			}
+		}
+		if len(units) == 0 {
+			continue
+		}
+
+		// Keep a trailing high surrogate until its pair arrives
+		var carry []uint16
+		if last := units[len(units)-1]; utf16.IsSurrogate(rune(last)) && last < 0xdc00 {
+			carry = []uint16{last}
+			units = units[:len(units)-1]
+		}
+
+		input := []byte(string(utf16.Decode(units)))
+		units = carry
+
+		if len(input) == 0 {
+			continue
+		}
+
+		pending = true
+		lastInput = c.clock.Now()
+
+		events, err := c.keyParser.Feed(input)
+		if err != nil {
+			continue // Skip unparseable input
+		}
+
+		if !c.sendEvents(events) {
+			return
+		}
+	}
+}
+
+// monitorWindowSize polls the console size and reports changes.
+func (c *ConsoleInput) monitorWindowSize() {
+	last, err := c.GetWindowSize()
+	if err == nil {
+		// Send initial size
+		select {
+		case c.sizeChan <- last:
+		case <-c.ctx.Done():
+			return
+		}
+	}
+
+	ticker := c.clock.NewTicker(windowSizePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			size, err := c.GetWindowSize()
+			if err != nil || size == last {
+				continue
+			}
+			last = size
+			select {
+			case c.sizeChan <- size:
+			case <-c.ctx.Done():
+				return
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// Close cleans up resources and restores the console mode.
+func (c *ConsoleInput) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rawMode {
+		// Restore console settings
+		windows.SetConsoleMode(c.in, c.origMode)
+		c.rawMode = false
+	}
+
+	windows.CloseHandle(c.in)
+	windows.CloseHandle(c.out)
+
+	close(c.inputChan)
+	close(c.sizeChan)
+
+	if c.keyParser != nil {
+		return c.keyParser.Close()
+	}
+
+	return nil
+}
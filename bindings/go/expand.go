@@ -0,0 +1,78 @@
+package keyparsing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandTilde replaces a leading "~" or "~/" in path with the current user's
+// home directory. Other forms, such as "~user/..." or a "~" that is not at
+// the start, are returned unchanged, as is path when the home directory
+// cannot be determined.
+func ExpandTilde(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	return home + path[1:]
+}
+
+// ExpandEnv replaces $VAR and ${VAR} references in s with the values of the
+// corresponding environment variables. Unlike os.ExpandEnv, references to
+// unset variables and unterminated "${VAR" forms are left as written, so text
+// the user is still typing is not silently dropped. Variables set to the
+// empty string expand to the empty string.
+func ExpandEnv(s string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		var name string
+		var end int
+		if s[i+1] == '{' {
+			closing := strings.IndexByte(s[i+2:], '}')
+			if closing < 0 {
+				// Unterminated ${VAR: keep the rest verbatim
+				b.WriteString(s[i:])
+				break
+			}
+			name = s[i+2 : i+2+closing]
+			end = i + 2 + closing + 1
+		} else {
+			end = i + 1
+			for end < len(s) && isEnvNameByte(s[end]) {
+				end++
+			}
+			name = s[i+1 : end]
+		}
+
+		if value, ok := os.LookupEnv(name); ok && name != "" {
+			b.WriteString(value)
+		} else {
+			b.WriteString(s[i:end])
+		}
+		i = max(end, i+1)
+	}
+
+	return b.String()
+}
+
+// isEnvNameByte reports whether c may appear in a $VAR reference.
+func isEnvNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
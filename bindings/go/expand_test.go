@@ -0,0 +1,60 @@
+package keyparsing
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"~", home},
+		{"~/projects", home + "/projects"},
+		{"~" + string(filepath.Separator) + "x", home + string(filepath.Separator) + "x"},
+		{"~other/projects", "~other/projects"},
+		{"/tmp/~", "/tmp/~"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := ExpandTilde(tc.input); got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("REPLKIT_NAME", "world")
+	t.Setenv("REPLKIT_EMPTY", "")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"hello $REPLKIT_NAME", "hello world"},
+		{"hello ${REPLKIT_NAME}!", "hello world!"},
+		{"$REPLKIT_NAME/$REPLKIT_NAME", "world/world"},
+		{"[$REPLKIT_EMPTY]", "[]"},
+		{"$REPLKIT_UNSET_VARIABLE stays", "$REPLKIT_UNSET_VARIABLE stays"},
+		{"${REPLKIT_NAME", "${REPLKIT_NAME"},
+		{"cost: $5 and $", "cost: $5 and $"},
+		{"${}", "${}"},
+		{"no variables", "no variables"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := ExpandEnv(tc.input); got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package keyparsing
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	wasmPageSize = 65536
+
+	// Smallest block handed out, which is also the block alignment
+	minSizeClass = 4
+	maxSizeClass = 31
+
+	// Pages claimed from the guest at a time for small blocks
+	heapGrowPages = 16
+)
+
+// guestHeaps implements the env.__wbindgen_malloc and env.__wbindgen_free
+// imports. The replkit module's exported malloc and free forward to these
+// imports, so the host cannot delegate back to the guest and has to manage
+// the memory itself: blocks are carved from pages grown on the calling
+// module's memory and recycled through power-of-two free lists. Each module
+// instance gets its own heap.
+type guestHeaps struct {
+	mu    sync.Mutex
+	heaps map[api.Module]*guestHeap
+}
+
+// guestHeap is the allocator state for one module instance.
+type guestHeap struct {
+	next, end uint64                     // Unused part of the current chunk
+	free      [maxSizeClass + 1][]uint32 // Freed blocks by size class
+}
+
+func newGuestHeaps() *guestHeaps {
+	return &guestHeaps{heaps: make(map[api.Module]*guestHeap)}
+}
+
+// instantiate registers the allocator as the "env" host module in runtime.
+func (h *guestHeaps) instantiate(ctx context.Context, runtime wazero.Runtime) error {
+	envBuilder := runtime.NewHostModuleBuilder("env")
+	envBuilder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, size uint32) uint32 {
+			return h.malloc(m, size)
+		}).
+		Export("__wbindgen_malloc")
+	envBuilder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, ptr uint32, size uint32) {
+			h.free(m, ptr, size)
+		}).
+		Export("__wbindgen_free")
+
+	_, err := envBuilder.Instantiate(ctx)
+	return err
+}
+
+// sizeClass returns the power-of-two class that holds size bytes.
+func sizeClass(size uint32) (int, bool) {
+	if size > 1<<maxSizeClass {
+		return 0, false
+	}
+	class := bits.Len32(size - 1)
+	if size == 0 || class < minSizeClass {
+		class = minSizeClass
+	}
+	return class, true
+}
+
+// malloc returns a block of at least size bytes in m's memory, or 0 when the
+// memory cannot grow.
+func (h *guestHeaps) malloc(m api.Module, size uint32) uint32 {
+	class, ok := sizeClass(size)
+	if !ok {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	heap := h.heaps[m]
+	if heap == nil {
+		heap = &guestHeap{}
+		h.heaps[m] = heap
+	}
+
+	if n := len(heap.free[class]); n > 0 {
+		ptr := heap.free[class][n-1]
+		heap.free[class] = heap.free[class][:n-1]
+		return ptr
+	}
+
+	blockSize := uint64(1) << class
+	if heap.end-heap.next < blockSize {
+		mem := m.Memory()
+		if mem == nil {
+			return 0
+		}
+
+		// Room for one extra block so a chunk at address 0 can skip it
+		pages := (blockSize + 1<<minSizeClass + wasmPageSize - 1) / wasmPageSize
+		if pages < heapGrowPages {
+			pages = heapGrowPages
+		}
+		prev, ok := mem.Grow(uint32(pages))
+		if !ok {
+			return 0
+		}
+
+		// The guest may have grown memory too, so start a fresh chunk
+		heap.next = uint64(prev) * wasmPageSize
+		heap.end = heap.next + pages*wasmPageSize
+		if heap.next == 0 {
+			heap.next = 1 << minSizeClass // Never hand out a null pointer
+		}
+	}
+
+	ptr := uint32(heap.next)
+	heap.next += blockSize
+	return ptr
+}
+
+// free returns a block from malloc to its free list. size must be the size
+// passed to malloc.
+func (h *guestHeaps) free(m api.Module, ptr uint32, size uint32) {
+	class, ok := sizeClass(size)
+	if ptr == 0 || !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if heap := h.heaps[m]; heap != nil {
+		heap.free[class] = append(heap.free[class], ptr)
+	}
+}
+
+// release drops the heap of a module instance that has been closed.
+func (h *guestHeaps) release(m api.Module) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.heaps, m)
+}
@@ -0,0 +1,137 @@
+package keyparsing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// wasmName encodes a short name as a WASM vector of bytes.
+func wasmName(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+// wasmSection encodes a section whose content is shorter than 128 bytes.
+func wasmSection(id byte, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	return append([]byte{id, byte(len(body))}, body...)
+}
+
+// allocatorGuest builds a minimal module that, like the replkit module,
+// imports the env allocator and calls it from its own exports:
+//
+//	(import "env" "__wbindgen_malloc" (func (param i32) (result i32)))
+//	(import "env" "__wbindgen_free" (func (param i32 i32)))
+//	(memory (export "memory") 1)
+//	(func (export "alloc") (param i32) (result i32) local.get 0 call 0)
+//	(func (export "dealloc") (param i32 i32) local.get 0 local.get 1 call 1)
+func allocatorGuest() []byte {
+	return bytes.Join([][]byte{
+		{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		wasmSection(1, []byte{0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x00}),
+		wasmSection(2, []byte{0x02},
+			wasmName("env"), wasmName("__wbindgen_malloc"), []byte{0x00, 0x00},
+			wasmName("env"), wasmName("__wbindgen_free"), []byte{0x00, 0x01}),
+		wasmSection(3, []byte{0x02, 0x00, 0x01}),
+		wasmSection(5, []byte{0x01, 0x00, 0x01}),
+		wasmSection(7, []byte{0x03},
+			wasmName("memory"), []byte{0x02, 0x00},
+			wasmName("alloc"), []byte{0x00, 0x02},
+			wasmName("dealloc"), []byte{0x00, 0x03}),
+		wasmSection(10, []byte{0x02},
+			[]byte{0x06, 0x00, 0x20, 0x00, 0x10, 0x00, 0x0b},
+			[]byte{0x08, 0x00, 0x20, 0x00, 0x20, 0x01, 0x10, 0x01, 0x0b}),
+	}, nil)
+}
+
+func TestGuestHeap(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	if err := newGuestHeaps().instantiate(ctx, runtime); err != nil {
+		t.Fatalf("Failed to instantiate env module: %v", err)
+	}
+	module, err := runtime.InstantiateWithConfig(ctx, allocatorGuest(), wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		t.Fatalf("Failed to instantiate guest: %v", err)
+	}
+
+	alloc := func(size uint32) uint32 {
+		results, err := module.ExportedFunction("alloc").Call(ctx, uint64(size))
+		if err != nil {
+			t.Fatalf("Failed to call alloc: %v", err)
+		}
+		ptr := uint32(results[0])
+		if ptr == 0 || uint64(ptr)+uint64(size) > uint64(module.Memory().Size()) {
+			t.Fatalf("Expected a block of %d bytes inside guest memory, got: %d", size, ptr)
+		}
+		return ptr
+	}
+	dealloc := func(ptr, size uint32) {
+		if _, err := module.ExportedFunction("dealloc").Call(ctx, uint64(ptr), uint64(size)); err != nil {
+			t.Fatalf("Failed to call dealloc: %v", err)
+		}
+	}
+
+	// Live blocks never overlap, so writing one does not corrupt another
+	sizes := []uint32{24, 24, 1, 0, 200, 100000, 24}
+	ptrs := make([]uint32, len(sizes))
+	for i, size := range sizes {
+		ptrs[i] = alloc(size)
+		if !module.Memory().Write(ptrs[i], bytes.Repeat([]byte{byte(i + 1)}, int(size))) {
+			t.Fatalf("Failed to write block %d", i)
+		}
+	}
+	for i, size := range sizes {
+		got, _ := module.Memory().Read(ptrs[i], size)
+		if !bytes.Equal(got, bytes.Repeat([]byte{byte(i + 1)}, int(size))) {
+			t.Errorf("Block %d (%d bytes at %d) was overwritten", i, size, ptrs[i])
+		}
+	}
+
+	// Freed blocks are reused for allocations of the same size class
+	dealloc(ptrs[1], sizes[1])
+	if ptr := alloc(20); ptr != ptrs[1] {
+		t.Errorf("Expected freed block %d to be reused, got: %d", ptrs[1], ptr)
+	}
+}
+
+func TestGuestHeapPerModule(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	heaps := newGuestHeaps()
+	if err := heaps.instantiate(ctx, runtime); err != nil {
+		t.Fatalf("Failed to instantiate env module: %v", err)
+	}
+
+	var modules []api.Module
+	for i := 0; i < 2; i++ {
+		module, err := runtime.InstantiateWithConfig(ctx, allocatorGuest(), wazero.NewModuleConfig().WithName(""))
+		if err != nil {
+			t.Fatalf("Failed to instantiate guest: %v", err)
+		}
+		modules = append(modules, module)
+	}
+
+	// Each instance allocates from its own memory
+	for _, module := range modules {
+		results, err := module.ExportedFunction("alloc").Call(ctx, 16)
+		if err != nil {
+			t.Fatalf("Failed to call alloc: %v", err)
+		}
+		if ptr := uint32(results[0]); ptr < wasmPageSize || ptr >= module.Memory().Size() {
+			t.Errorf("Expected a block in grown pages of the calling module, got: %d", ptr)
+		}
+	}
+
+	heaps.release(modules[0])
+	if len(heaps.heaps) != 1 {
+		t.Errorf("Expected one heap after release, got: %d", len(heaps.heaps))
+	}
+}
@@ -138,3 +138,38 @@ func TestWASMIntegration(t *testing.T) {
 		t.Errorf("Expected second event to be Right, got %v", events[1].Key)
 	}
 }
+
+func TestIgnoredSequenceRawBytes(t *testing.T) {
+	wasmPath := filepath.Join("wasm", "replkit_wasm.wasm")
+	wasmBytes, err := ioutil.ReadFile(wasmPath)
+	if err != nil {
+		t.Skipf("WASM binary not found at %s, skipping integration test: %v", wasmPath, err)
+		return
+	}
+
+	parser, err := NewKeyParser(context.Background(), wasmBytes)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	// ESC[E is swallowed by xterm-compatible parsers and maps to Ignore
+	input := []byte{0x1b, 0x5b, 0x45}
+	events, err := parser.Feed(input)
+	if err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].Key != Ignore {
+		t.Errorf("Expected Ignore key, got %v", events[0].Key)
+	}
+	if string(events[0].RawBytes) != string(input) {
+		t.Errorf("Expected raw bytes %v, got %v", input, events[0].RawBytes)
+	}
+	if events[0].Text != nil {
+		t.Errorf("Expected no text for ignored sequence, got %q", *events[0].Text)
+	}
+}
@@ -0,0 +1,83 @@
+package keyparsing
+
+import "strings"
+
+// KeyCombo is a key together with the modifiers the Key enum cannot express,
+// as used in key bindings. Ctrl and Shift are folded into Key where a
+// dedicated value exists (ControlA, ShiftUp), so Mods is usually zero.
+type KeyCombo struct {
+	Key  Key
+	Mods Modifiers
+}
+
+// keyAliases pairs keys that the terminal sends as the same byte. The parser
+// reports the first of each pair (0x0d is Enter, never ControlM).
+var keyAliases = map[Key]Key{
+	Enter:    ControlM,
+	ControlM: Enter,
+	Tab:      ControlI,
+	ControlI: Tab,
+}
+
+// ParseKeyCombo parses a key name such as "ctrl+l" or "alt+left" into a
+// KeyCombo. See ParseKeyName for the accepted syntax.
+func ParseKeyCombo(s string) (KeyCombo, error) {
+	key, mods, err := ParseKeyName(s)
+	if err != nil {
+		return KeyCombo{}, err
+	}
+	return KeyCombo{Key: key, Mods: mods}, nil
+}
+
+// String returns the combo's key name, such as "ctrl+l" or "alt+up".
+// ParseKeyCombo accepts every name returned here.
+func (c KeyCombo) String() string {
+	var b strings.Builder
+	if c.Mods&ModCtrl != 0 {
+		b.WriteString("ctrl+")
+	}
+	if c.Mods&ModShift != 0 {
+		b.WriteString("shift+")
+	}
+	if c.Mods&ModAlt != 0 {
+		b.WriteString("alt+")
+	}
+	b.WriteString(c.Key.Name())
+	return b.String()
+}
+
+// Matches reports whether event is a press of this combo. Keys sharing a byte
+// match each other, so ControlM matches the Enter event the parser produces
+// for 0x0d. Key events carry no modifiers, and Alt arrives as a separate
+// Escape event, so a combo with Mods set never matches a single event.
+func (c KeyCombo) Matches(event KeyEvent) bool {
+	if c.Mods != 0 {
+		return false
+	}
+	if event.Key == c.Key {
+		return true
+	}
+	alias, ok := keyAliases[event.Key]
+	return ok && alias == c.Key
+}
+
+// KeyMap binds key combos to actions, letting a read loop apply user
+// overrides before its default key handling.
+type KeyMap map[KeyCombo]func()
+
+// Dispatch runs the action bound to event and reports whether one was found.
+// A binding for the event's own key takes precedence over one for an alias.
+// Callers fall through to their default handling when it returns false.
+func (m KeyMap) Dispatch(event KeyEvent) bool {
+	action, ok := m[KeyCombo{Key: event.Key}]
+	if !ok {
+		if alias, hasAlias := keyAliases[event.Key]; hasAlias {
+			action, ok = m[KeyCombo{Key: alias}]
+		}
+	}
+	if !ok || action == nil {
+		return false
+	}
+	action()
+	return true
+}
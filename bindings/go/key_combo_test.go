@@ -0,0 +1,104 @@
+package keyparsing
+
+import "testing"
+
+func TestParseKeyCombo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected KeyCombo
+		str      string
+	}{
+		{"ctrl+l", KeyCombo{Key: ControlL}, "ctrl+l"},
+		{"Ctrl+M", KeyCombo{Key: ControlM}, "ctrl+m"},
+		{"enter", KeyCombo{Key: Enter}, "enter"},
+		{"alt+up", KeyCombo{Key: Up, Mods: ModAlt}, "alt+up"},
+		{"meta+ctrl+enter", KeyCombo{Key: Enter, Mods: ModCtrl | ModAlt}, "ctrl+alt+enter"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			combo, err := ParseKeyCombo(tc.name)
+			if err != nil {
+				t.Fatalf("Failed to parse key combo: %v", err)
+			}
+			if combo != tc.expected {
+				t.Errorf("Expected %+v, got: %+v", tc.expected, combo)
+			}
+			if got := combo.String(); got != tc.str {
+				t.Errorf("Expected %q, got: %q", tc.str, got)
+			}
+		})
+	}
+
+	if _, err := ParseKeyCombo("ctrl+nope"); err == nil {
+		t.Error("Expected error for an unknown key")
+	}
+}
+
+func TestKeyComboMatches(t *testing.T) {
+	// Events as the parser produces them for the raw bytes
+	enter := KeyEvent{Key: Enter, RawBytes: []byte{0x0d}}
+	tab := KeyEvent{Key: Tab, RawBytes: []byte{0x09}}
+	ctrlL := KeyEvent{Key: ControlL, RawBytes: []byte{0x0c}}
+	escape := KeyEvent{Key: Escape, RawBytes: []byte{0x1b}}
+
+	testCases := []struct {
+		name     string
+		combo    KeyCombo
+		event    KeyEvent
+		expected bool
+	}{
+		{"exact key", KeyCombo{Key: ControlL}, ctrlL, true},
+		{"different key", KeyCombo{Key: ControlL}, enter, false},
+		{"ctrl+m matches enter", KeyCombo{Key: ControlM}, enter, true},
+		{"enter matches enter", KeyCombo{Key: Enter}, enter, true},
+		{"ctrl+i matches tab", KeyCombo{Key: ControlI}, tab, true},
+		{"ctrl+m does not match tab", KeyCombo{Key: ControlM}, tab, false},
+		{"escape needs an exact match", KeyCombo{Key: Escape}, ctrlL, false},
+		{"escape", KeyCombo{Key: Escape}, escape, true},
+		{"modifiers never match a single event", KeyCombo{Key: Enter, Mods: ModAlt}, enter, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.combo.Matches(tc.event); got != tc.expected {
+				t.Errorf("Expected %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestKeyMapDispatch(t *testing.T) {
+	var called []string
+	keyMap := KeyMap{
+		{Key: ControlL}: func() { called = append(called, "clear") },
+		{Key: ControlM}: func() { called = append(called, "ctrl+m") },
+	}
+
+	if !keyMap.Dispatch(KeyEvent{Key: ControlL}) {
+		t.Error("Expected ctrl+l to be handled")
+	}
+	// ControlM is bound and Enter is not, so Enter falls back to the alias
+	if !keyMap.Dispatch(KeyEvent{Key: Enter}) {
+		t.Error("Expected enter to be handled by the ctrl+m binding")
+	}
+	// Unbound keys fall through to the caller's defaults
+	if keyMap.Dispatch(KeyEvent{Key: Up}) {
+		t.Error("Expected up to fall through")
+	}
+
+	// A binding for the key itself takes precedence over its alias
+	keyMap[KeyCombo{Key: Enter}] = func() { called = append(called, "enter") }
+	keyMap.Dispatch(KeyEvent{Key: Enter})
+
+	expected := []string{"clear", "ctrl+m", "enter"}
+	if len(called) != len(expected) {
+		t.Fatalf("Expected actions %v, got: %v", expected, called)
+	}
+	for i := range expected {
+		if called[i] != expected[i] {
+			t.Errorf("Expected actions %v, got: %v", expected, called)
+			break
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package keyparsing
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Modifiers is a set of modifier keys in a key name such as "alt+up".
+type Modifiers uint8
+
+const (
+	ModCtrl Modifiers = 1 << iota
+	ModShift
+	ModAlt
+)
+
+// modifierNames maps the accepted spellings of each modifier in key names.
+var modifierNames = map[string]Modifiers{
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"c":       ModCtrl,
+	"shift":   ModShift,
+	"s":       ModShift,
+	"alt":     ModAlt,
+	"meta":    ModAlt,
+	"option":  ModAlt,
+	"m":       ModAlt,
+}
+
+// keyNameAliases maps alternative spellings to canonical key names.
+var keyNameAliases = map[string]string{
+	"esc":     "escape",
+	"return":  "enter",
+	"del":     "delete",
+	"ins":     "insert",
+	"pgup":    "pageup",
+	"pgdn":    "pagedown",
+	"pgdown":  "pagedown",
+	"backtab": "shift+tab",
+}
+
+var (
+	keysByNameOnce sync.Once
+	keysByName     map[string]Key
+)
+
+// Name returns the canonical lowercase name of the key as used in keybinding
+// configuration, such as "ctrl+a", "shift+up", "shift+tab" or "pageup".
+// ParseKeyName accepts every name returned here.
+func (k Key) Name() string {
+	return strings.ToLower(k.DisplayName())
+}
+
+// ParseKeyName parses a key name such as "ctrl+a", "Up" or "alt+shift+left".
+// Names are case-insensitive and modifiers are joined with '+'. Modifiers the
+// Key enum can express are folded into the returned Key ("ctrl+a" is
+// ControlA, "shift+up" is ShiftUp); any others, notably Alt, are returned in
+// Modifiers. Plain printable characters have no Key value and are rejected.
+func ParseKeyName(s string) (Key, Modifiers, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "+")
+	base := parts[len(parts)-1]
+	if base == "" {
+		return NotDefined, 0, fmt.Errorf("invalid key name %q: missing key", s)
+	}
+	if alias, ok := keyNameAliases[base]; ok {
+		base = alias
+	}
+
+	var mods Modifiers
+	for _, part := range parts[:len(parts)-1] {
+		mod, ok := modifierNames[part]
+		if !ok {
+			return NotDefined, 0, fmt.Errorf("invalid key name %q: unknown modifier %q", s, part)
+		}
+		mods |= mod
+	}
+
+	keysByNameOnce.Do(func() {
+		keysByName = make(map[string]Key, NotDefined+1)
+		for k := Escape; k <= NotDefined; k++ {
+			keysByName[k.Name()] = k
+		}
+	})
+
+	// Fold Ctrl or Shift into the key when a dedicated Key value exists
+	for _, folded := range []Modifiers{ModCtrl, ModShift, 0} {
+		if mods&folded != folded {
+			continue
+		}
+		name := base
+		switch folded {
+		case ModCtrl:
+			name = "ctrl+" + base
+		case ModShift:
+			name = "shift+" + base
+		}
+		if key, ok := keysByName[name]; ok {
+			return key, mods &^ folded, nil
+		}
+	}
+
+	return NotDefined, 0, fmt.Errorf("invalid key name %q: unknown key %q", s, base)
+}
@@ -0,0 +1,84 @@
+package keyparsing
+
+import "testing"
+
+func TestKeyName(t *testing.T) {
+	testCases := []struct {
+		key      Key
+		expected string
+	}{
+		{ControlA, "ctrl+a"},
+		{ControlSpace, "ctrl+space"},
+		{ControlBackslash, "ctrl+\\"},
+		{Up, "up"},
+		{ShiftUp, "shift+up"},
+		{ControlLeft, "ctrl+left"},
+		{BackTab, "shift+tab"},
+		{PageDown, "pagedown"},
+		{F12, "f12"},
+		{Escape, "escape"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if got := tc.key.Name(); got != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseKeyName(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  Key
+		mods Modifiers
+	}{
+		{"ctrl+a", ControlA, 0},
+		{"Ctrl+A", ControlA, 0},
+		{"control+a", ControlA, 0},
+		{"up", Up, 0},
+		{"shift+up", ShiftUp, 0},
+		{"shift+tab", BackTab, 0},
+		{"backtab", BackTab, 0},
+		{"esc", Escape, 0},
+		{"return", Enter, 0},
+		{"pgdn", PageDown, 0},
+		{"ctrl+space", ControlSpace, 0},
+		{"ctrl+]", ControlSquareClose, 0},
+		{"alt+up", Up, ModAlt},
+		{"meta+ctrl+left", ControlLeft, ModAlt},
+		{"ctrl+enter", Enter, ModCtrl},
+		{"shift+ctrl+right", ControlRight, ModShift},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, mods, err := ParseKeyName(tc.name)
+			if err != nil {
+				t.Fatalf("Failed to parse key name: %v", err)
+			}
+			if key != tc.key || mods != tc.mods {
+				t.Errorf("Expected (%v, %d), got: (%v, %d)", tc.key, tc.mods, key, mods)
+			}
+		})
+	}
+
+	// Every canonical name round-trips
+	for k := Escape; k <= NotDefined; k++ {
+		key, mods, err := ParseKeyName(k.Name())
+		if err != nil || key != k || mods != 0 {
+			t.Errorf("Expected %q to parse as %v, got: %v, %d, %v", k.Name(), k, key, mods, err)
+		}
+	}
+}
+
+func TestParseKeyNameInvalid(t *testing.T) {
+	for _, name := range []string{"", "ctrl+", "hyper+a", "a", "ctrl+shift+foo", "notakey"} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseKeyName(name); err == nil {
+				t.Errorf("Expected an error for %q", name)
+			}
+		})
+	}
+}
@@ -1,19 +1,29 @@
 package keyparsing
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 //go:embed wasm/replkit_wasm.wasm
 var embeddedWasm []byte
 
+// ErrClosed is returned when a KeyParser is used after Close has been called.
+var ErrClosed = errors.New("parser has been closed")
+
 // Key represents the different types of keys that can be parsed.
 // These constants must match the u32 values from the Rust WASM module.
 //
@@ -111,17 +121,113 @@ const (
 	NotDefined         Key = 86
 )
 
+// keyDisplayNames holds display names that cannot be derived from the
+// enum identifier.
+var keyDisplayNames = map[Key]string{
+	ControlSpace:       "Ctrl+Space",
+	ControlBackslash:   "Ctrl+\\",
+	ControlSquareClose: "Ctrl+]",
+	ControlCircumflex:  "Ctrl+^",
+	ControlUnderscore:  "Ctrl+_",
+	BackTab:            "Shift+Tab",
+}
+
+// DisplayName returns a human-friendly name for the key such as "Ctrl+A",
+// "Shift+Up" or "Ctrl+Left". String returns the enum identifier instead.
+func (k Key) DisplayName() string {
+	if name, ok := keyDisplayNames[k]; ok {
+		return name
+	}
+
+	name := k.String()
+	switch {
+	case strings.HasPrefix(name, "Key("):
+		return name
+	case strings.HasPrefix(name, "Control"):
+		return "Ctrl+" + strings.TrimPrefix(name, "Control")
+	case strings.HasPrefix(name, "Shift"):
+		return "Shift+" + strings.TrimPrefix(name, "Shift")
+	default:
+		return name
+	}
+}
+
 // KeyEvent represents a parsed key event with the key type, raw bytes, and optional text
 type KeyEvent struct {
-	Key      Key     `json:"key"`            // The parsed key type
-	RawBytes []byte  `json:"raw_bytes"`      // The original raw bytes that produced this key event
-	Text     *string `json:"text,omitempty"` // Optional text representation (for printable characters)
+	Key        Key     `json:"key"`                  // The parsed key type
+	RawBytes   []byte  `json:"raw_bytes"`            // The original raw bytes that produced this key event
+	Text       *string `json:"text,omitempty"`       // Optional text representation (for printable characters)
+	Incomplete bool    `json:"incomplete,omitempty"` // Set on interim bracketed paste chunks (see SetIncrementalPaste)
 }
 
-// KeyParser wraps the WASM-based key parser
+// ParseCPR decodes the row and column from a Cursor Position Report
+// (ESC [ row ; col R), as delivered in the raw bytes of a CPRResponse event.
+// Missing parameters default to 1, so "\x1b[R" reports (1, 1).
+// ok is false if the raw bytes are not a well-formed CPR sequence.
+func ParseCPR(event KeyEvent) (row, col int, ok bool) {
+	raw := event.RawBytes
+	if len(raw) < 3 || raw[0] != 0x1b || raw[1] != '[' || raw[len(raw)-1] != 'R' {
+		return 0, 0, false
+	}
+
+	params := strings.Split(string(raw[2:len(raw)-1]), ";")
+	if len(params) > 2 {
+		return 0, 0, false
+	}
+
+	values := [2]int{1, 1}
+	for i, param := range params {
+		if param == "" {
+			continue
+		}
+		for _, c := range param {
+			if c < '0' || c > '9' {
+				return 0, 0, false
+			}
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, 0, false
+		}
+		if n > 0 {
+			values[i] = n
+		}
+	}
+
+	return values[0], values[1], true
+}
+
+// PasteContent returns the pasted text carried by a BracketedPaste event.
+// The parser already strips the ESC[200~ / ESC[201~ markers and buffers the
+// paste across Feed calls, delivering it as a single event (or as chunks when
+// SetIncrementalPaste is enabled, in which case this returns one chunk); any
+// markers still present in the raw bytes are removed here as well. Escape sequences inside
+// the paste are returned verbatim rather than being interpreted as keys.
+func PasteContent(event KeyEvent) (string, error) {
+	if event.Key != BracketedPaste {
+		return "", fmt.Errorf("not a bracketed paste event: %v", event.Key)
+	}
+	if event.Text != nil {
+		return *event.Text, nil
+	}
+
+	content := bytes.TrimPrefix(event.RawBytes, []byte("\x1b[200~"))
+	content = bytes.TrimSuffix(content, []byte("\x1b[201~"))
+	if !utf8.Valid(content) {
+		return "", fmt.Errorf("bracketed paste content is not valid UTF-8")
+	}
+	return string(content), nil
+}
+
+// KeyParser wraps the WASM-based key parser.
+//
+// Feed, FeedString, Flush, Reset, HealthCheck and Close may be called from
+// multiple goroutines. Buffers and Documents created by the parser share its WASM
+// instance without this locking and must not be used concurrently with it.
 type KeyParser struct {
-	runtime wazero.Runtime
+	runtime wazero.Runtime // Set when the parser owns its runtime (see NewKeyParser)
 	module  api.Module
+	heaps   *guestHeaps // Allocator backing the module's malloc and free
 	ctx     context.Context
 
 	// WASM function handles
@@ -133,6 +239,12 @@ type KeyParser struct {
 
 	// Parser instance ID in WASM memory
 	parserID uint32
+
+	// Serializes Feed, Flush, Reset and Close, which share WASM memory
+	mu sync.Mutex
+
+	// Merge runs of printable text events from a single Feed
+	coalesceText bool
 }
 
 // New creates a new KeyParser instance using the embedded WASM binary.
@@ -166,104 +278,20 @@ func New(ctx context.Context) (*KeyParser, error) {
 //	}
 //	defer parser.Close()
 func NewKeyParser(ctx context.Context, wasmBytes []byte) (*KeyParser, error) {
-	if len(wasmBytes) == 0 {
-		return nil, fmt.Errorf("WASM binary cannot be empty")
-	}
-	// Create a new WASM runtime
-	runtime := wazero.NewRuntime(ctx)
-
-	// Instantiate WASI to support basic system calls
-	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
-	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
-	}
-
-	// Create env module for WASM malloc/free functions
-	envBuilder := runtime.NewHostModuleBuilder("env")
-	envBuilder.NewFunctionBuilder().
-		WithFunc(func(ctx context.Context, size uint32) uint32 {
-			// Simple allocator - in production you'd want a proper allocator
-			return size // This is a placeholder - the WASM module should handle its own allocation
-		}).
-		Export("__wbindgen_malloc")
-	envBuilder.NewFunctionBuilder().
-		WithFunc(func(ctx context.Context, ptr uint32, size uint32) {
-			// Simple deallocator - the WASM module handles its own deallocation
-			// This is a no-op since WASM has its own memory management
-		}).
-		Export("__wbindgen_free")
-
-	_, err = envBuilder.Instantiate(ctx)
+	rt, err := NewRuntime(ctx, wasmBytes)
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to instantiate env module: %w", err)
+		return nil, err
 	}
 
-	// Compile and instantiate the WASM module
-	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	parser, err := rt.NewParser()
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to compile WASM module: %w", err)
-	}
-
-	module, err := runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig())
-	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to instantiate WASM module: %w", err)
-	}
-
-	// Get function handles
-	newParserFn := module.ExportedFunction("new_parser")
-	if newParserFn == nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("WASM module does not export 'new_parser' function")
-	}
-
-	feedFn := module.ExportedFunction("feed")
-	if feedFn == nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("WASM module does not export 'feed' function")
+		rt.Close()
+		return nil, err
 	}
 
-	flushFn := module.ExportedFunction("flush")
-	if flushFn == nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("WASM module does not export 'flush' function")
-	}
-
-	resetFn := module.ExportedFunction("reset")
-	if resetFn == nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("WASM module does not export 'reset' function")
-	}
-
-	destroyFn := module.ExportedFunction("destroy_parser")
-	if destroyFn == nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("WASM module does not export 'destroy_parser' function")
-	}
-
-	// Create a new parser instance in WASM
-	results, err := newParserFn.Call(ctx)
-	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to create parser instance: %w", err)
-	}
-
-	parserID := uint32(results[0])
-
-	return &KeyParser{
-		runtime:     runtime,
-		module:      module,
-		ctx:         ctx,
-		newParserFn: newParserFn,
-		feedFn:      feedFn,
-		flushFn:     flushFn,
-		resetFn:     resetFn,
-		destroyFn:   destroyFn,
-		parserID:    parserID,
-	}, nil
+	// The parser has the runtime to itself and closes it on Close
+	parser.runtime = rt.runtime
+	return parser, nil
 }
 
 // Feed processes input bytes and returns parsed key events.
@@ -273,8 +301,11 @@ func (p *KeyParser) Feed(input []byte) ([]KeyEvent, error) {
 	if p == nil {
 		return nil, fmt.Errorf("parser is nil")
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.module == nil {
-		return nil, fmt.Errorf("parser has been closed")
+		return nil, ErrClosed
 	}
 	if len(input) == 0 {
 		return nil, nil
@@ -298,8 +329,39 @@ func (p *KeyParser) Feed(input []byte) ([]KeyEvent, error) {
 		return nil, fmt.Errorf("failed to write input to WASM memory")
 	}
 
+	return p.feedAt(inputPtr, len(input))
+}
+
+// FeedString processes input text and returns parsed key events.
+// It behaves exactly like Feed([]byte(s)), including buffering of partial
+// sequences across calls, but writes the string to WASM memory directly.
+func (p *KeyParser) FeedString(s string) ([]KeyEvent, error) {
+	if p == nil {
+		return nil, fmt.Errorf("parser is nil")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.module == nil {
+		return nil, ErrClosed
+	}
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	inputPtr, err := p.allocateString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.feedAt(inputPtr, len(s))
+}
+
+// feedAt calls the WASM feed function on input already written at inputPtr,
+// frees the input and decodes the resulting key events.
+func (p *KeyParser) feedAt(inputPtr uint32, length int) ([]KeyEvent, error) {
 	// Call the feed function
-	results, err = p.feedFn.Call(p.ctx, uint64(p.parserID), uint64(inputPtr), uint64(len(input)))
+	results, err := p.feedFn.Call(p.ctx, uint64(p.parserID), uint64(inputPtr), uint64(length))
 	if err != nil {
 		return nil, fmt.Errorf("failed to call feed function: %w", err)
 	}
@@ -336,17 +398,97 @@ func (p *KeyParser) Feed(input []byte) ([]KeyEvent, error) {
 		return nil, fmt.Errorf("failed to parse key events JSON: %w", err)
 	}
 
+	if p.coalesceText {
+		events = coalesceTextEvents(events)
+	}
+
 	return events, nil
 }
 
+// SetCoalesceText controls whether consecutive printable NotDefined events
+// produced by a single Feed call are merged into one event whose Text and
+// RawBytes are the concatenation of the originals. This lets callers insert
+// unbracketed pastes in one operation. Events are never merged across Feed
+// calls or across control keys.
+func (p *KeyParser) SetCoalesceText(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.coalesceText = enabled
+}
+
+// SetIncrementalPaste controls whether bracketed paste content is delivered
+// as it arrives. When enabled, every Feed made while a paste is in progress
+// returns the content received so far as a BracketedPaste event with
+// Incomplete set, and the paste's final event carries the rest with
+// Incomplete unset. Concatenating the Text of all chunks yields the full
+// paste. When disabled (the default) a paste produces a single event once
+// its end sequence arrives.
+func (p *KeyParser) SetIncrementalPaste(enabled bool) error {
+	if p == nil {
+		return fmt.Errorf("parser is nil")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.module == nil {
+		return ErrClosed
+	}
+
+	setIncrementalPasteFn := p.module.ExportedFunction("set_incremental_paste")
+	if setIncrementalPasteFn == nil {
+		return fmt.Errorf("WASM module does not export 'set_incremental_paste' function")
+	}
+
+	var flag uint64
+	if enabled {
+		flag = 1
+	}
+	if _, err := setIncrementalPasteFn.Call(p.ctx, uint64(p.parserID), flag); err != nil {
+		return fmt.Errorf("failed to call set_incremental_paste function: %w", err)
+	}
+	return nil
+}
+
+// coalesceTextEvents merges runs of printable text events.
+func coalesceTextEvents(events []KeyEvent) []KeyEvent {
+	if len(events) < 2 {
+		return events
+	}
+
+	result := make([]KeyEvent, 0, len(events))
+	for _, event := range events {
+		if n := len(result); n > 0 && isPrintableText(result[n-1]) && isPrintableText(event) {
+			last := &result[n-1]
+			text := *last.Text + *event.Text
+			last.Text = &text
+			last.RawBytes = append(append([]byte(nil), last.RawBytes...), event.RawBytes...)
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+// isPrintableText reports whether event is a NotDefined event carrying text
+// without control characters.
+func isPrintableText(event KeyEvent) bool {
+	if event.Key != NotDefined || event.Text == nil || *event.Text == "" {
+		return false
+	}
+	return strings.IndexFunc(*event.Text, unicode.IsControl) < 0
+}
+
 // Flush processes any remaining buffered input and returns key events.
 // This should be called when input is complete to handle any partial sequences.
 func (p *KeyParser) Flush() ([]KeyEvent, error) {
 	if p == nil {
 		return nil, fmt.Errorf("parser is nil")
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.module == nil {
-		return nil, fmt.Errorf("parser has been closed")
+		return nil, ErrClosed
 	}
 	results, err := p.flushFn.Call(p.ctx, uint64(p.parserID))
 	if err != nil {
@@ -381,13 +523,110 @@ func (p *KeyParser) Flush() ([]KeyEvent, error) {
 	return events, nil
 }
 
+// Events reads from r in a goroutine, feeds the bytes through the parser and
+// sends the decoded key events on the returned channel. When r reports
+// io.EOF, any buffered partial sequence (such as a lone escape) is flushed
+// before the channel is closed. The channel is also closed when ctx is
+// cancelled or r returns another error; a Read that is already blocked is
+// not interrupted by cancellation.
+//
+// Feed and Flush calls from other goroutines are serialized with the stream
+// but interleave with it, so they are rarely useful while it is active.
+func (p *KeyParser) Events(ctx context.Context, r io.Reader) <-chan KeyEvent {
+	ch := make(chan KeyEvent)
+
+	go func() {
+		defer close(ch)
+
+		send := func(events []KeyEvent) bool {
+			for _, event := range events {
+				select {
+				case ch <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		buf := make([]byte, 1024)
+		for {
+			n, err := r.Read(buf)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if n > 0 {
+				events, feedErr := p.Feed(buf[:n])
+				if feedErr != nil || !send(events) {
+					return
+				}
+			}
+
+			if err == io.EOF {
+				if events, flushErr := p.Flush(); flushErr == nil {
+					send(events)
+				}
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// feedReaderChunkSize bounds the buffer FeedReader reads into.
+const feedReaderChunkSize = 4096
+
+// FeedReader reads r to EOF, feeding each chunk through the parser, and
+// returns all decoded events followed by those from a final Flush. It is
+// meant for replaying recorded input in one call. A read error other than
+// io.EOF stops reading and is returned with the events decoded so far.
+func (p *KeyParser) FeedReader(r io.Reader) ([]KeyEvent, error) {
+	if r == nil {
+		return nil, fmt.Errorf("reader is nil")
+	}
+
+	var events []KeyEvent
+	buf := make([]byte, feedReaderChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk, feedErr := p.Feed(buf[:n])
+			if feedErr != nil {
+				return events, feedErr
+			}
+			events = append(events, chunk...)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return events, err
+		}
+	}
+
+	flushed, err := p.Flush()
+	if err != nil {
+		return events, err
+	}
+	return append(events, flushed...), nil
+}
+
 // Reset clears the parser state, discarding any buffered partial sequences.
 func (p *KeyParser) Reset() error {
 	if p == nil {
 		return fmt.Errorf("parser is nil")
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.module == nil {
-		return fmt.Errorf("parser has been closed")
+		return ErrClosed
 	}
 	_, err := p.resetFn.Call(p.ctx, uint64(p.parserID))
 	if err != nil {
@@ -396,12 +635,57 @@ func (p *KeyParser) Reset() error {
 	return nil
 }
 
+// HealthCheck verifies that the WASM runtime is still operational by creating
+// a buffer, inserting "x", reading it back and destroying the buffer.
+// It returns ErrClosed if the parser has been closed, or an error describing
+// the first step that failed or deviated from the expected result.
+func (p *KeyParser) HealthCheck() error {
+	if p == nil {
+		return fmt.Errorf("parser is nil")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.module == nil {
+		return ErrClosed
+	}
+
+	buffer, err := p.NewBuffer()
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	if err := buffer.InsertText("x", false, true); err != nil {
+		buffer.Close()
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	text, err := buffer.Text()
+	if err != nil {
+		buffer.Close()
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	if text != "x" {
+		buffer.Close()
+		return fmt.Errorf("health check failed: expected buffer text %q, got %q", "x", text)
+	}
+
+	if err := buffer.Close(); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
+
 // Close releases all resources and marks the parser as closed.
 // After calling Close, the parser cannot be used anymore.
 func (p *KeyParser) Close() error {
 	if p == nil {
 		return fmt.Errorf("parser is nil")
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.module == nil {
 		return nil // Already closed
 	}
@@ -410,7 +694,14 @@ func (p *KeyParser) Close() error {
 		p.destroyFn.Call(p.ctx, uint64(p.parserID))
 	}
 
-	err := p.runtime.Close(p.ctx)
+	// A parser from a shared Runtime closes only its own module instance
+	var err error
+	if p.runtime != nil {
+		err = p.runtime.Close(p.ctx)
+	} else {
+		err = p.module.Close(p.ctx)
+		p.heaps.release(p.module)
+	}
 
 	// Mark as closed to prevent further use
 	p.module = nil
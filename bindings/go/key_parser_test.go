@@ -1,8 +1,16 @@
 package keyparsing
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 func TestKeyParserCreation(t *testing.T) {
@@ -64,18 +72,19 @@ func TestKeyParserMethods(t *testing.T) {
 
 	// These should compile without errors
 	_ = func() ([]KeyEvent, error) { return parser.Feed([]byte{}) }
+	_ = func() ([]KeyEvent, error) { return parser.FeedString("") }
 	_ = func() ([]KeyEvent, error) { return parser.Flush() }
 	_ = func() error { return parser.Reset() }
 	_ = func() error { return parser.Close() }
 }
 func TestKeyStringRepresentation(t *testing.T) {
-	// Test that keys have proper string representations
+	// String returns the enum identifier
 	testCases := []struct {
 		key      Key
 		expected string
 	}{
-		{ControlA, "Ctrl+A"},
-		{ControlC, "Ctrl+C"},
+		{ControlA, "ControlA"},
+		{ControlC, "ControlC"},
 		{Up, "Up"},
 		{Down, "Down"},
 		{Left, "Left"},
@@ -85,8 +94,8 @@ func TestKeyStringRepresentation(t *testing.T) {
 		{Tab, "Tab"},
 		{Enter, "Enter"},
 		{Escape, "Escape"},
-		{ShiftUp, "Shift+Up"},
-		{ControlLeft, "Ctrl+Left"},
+		{ShiftUp, "ShiftUp"},
+		{ControlLeft, "ControlLeft"},
 		{NotDefined, "NotDefined"},
 		{Ignore, "Ignore"},
 	}
@@ -104,6 +113,37 @@ func TestKeyStringRepresentation(t *testing.T) {
 		t.Errorf("Expected unknown key string to be %q, got %q", expected, unknownKey.String())
 	}
 }
+
+func TestKeyDisplayName(t *testing.T) {
+	testCases := []struct {
+		key      Key
+		expected string
+	}{
+		{ControlA, "Ctrl+A"},
+		{ControlC, "Ctrl+C"},
+		{ControlLeft, "Ctrl+Left"},
+		{ControlDelete, "Ctrl+Delete"},
+		{ControlSpace, "Ctrl+Space"},
+		{ControlBackslash, "Ctrl+\\"},
+		{ControlSquareClose, "Ctrl+]"},
+		{ShiftUp, "Shift+Up"},
+		{ShiftDelete, "Shift+Delete"},
+		{BackTab, "Shift+Tab"},
+		{Up, "Up"},
+		{F12, "F12"},
+		{Enter, "Enter"},
+		{Escape, "Escape"},
+		{NotDefined, "NotDefined"},
+		{Key(999), "Key(999)"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.key.DisplayName(); got != tc.expected {
+			t.Errorf("Expected %s.DisplayName() to be %q, got %q", tc.key, tc.expected, got)
+		}
+	}
+}
+
 func TestKeyParserErrorHandling(t *testing.T) {
 	// Test error handling for nil parser
 	var parser *KeyParser
@@ -128,3 +168,528 @@ func TestKeyParserErrorHandling(t *testing.T) {
 		t.Error("Expected error when calling Close on nil parser")
 	}
 }
+
+func TestKeyParserFeedString(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []string{
+		"héllo",
+		"日本語",
+		"a\x1b[Ab",
+	}
+
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			byteParser, err := New(ctx)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			defer byteParser.Close()
+
+			stringParser, err := New(ctx)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			defer stringParser.Close()
+
+			expected, err := byteParser.Feed([]byte(input))
+			if err != nil {
+				t.Fatalf("Failed to feed bytes: %v", err)
+			}
+
+			events, err := stringParser.FeedString(input)
+			if err != nil {
+				t.Fatalf("Failed to feed string: %v", err)
+			}
+
+			if !reflect.DeepEqual(events, expected) {
+				t.Errorf("Expected FeedString events %+v to match Feed events %+v", events, expected)
+			}
+		})
+	}
+}
+
+func TestKeyParserFeedStringPartialSequence(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	events, err := parser.FeedString("\x1b[")
+	if err != nil {
+		t.Fatalf("Failed to feed partial sequence: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected 0 events for partial sequence, got %d", len(events))
+	}
+
+	events, err = parser.FeedString("A")
+	if err != nil {
+		t.Fatalf("Failed to complete sequence: %v", err)
+	}
+	if len(events) != 1 || events[0].Key != Up {
+		t.Errorf("Expected a single Up event, got %+v", events)
+	}
+
+	events, err = parser.FeedString("")
+	if err != nil {
+		t.Fatalf("Failed to feed empty string: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected 0 events for empty string, got %d", len(events))
+	}
+}
+
+func TestParseCPR(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		row, col int
+		ok       bool
+	}{
+		{"typical", "\x1b[12;40R", 12, 40, true},
+		{"large values", "\x1b[120;300R", 120, 300, true},
+		{"missing both", "\x1b[R", 1, 1, true},
+		{"missing column", "\x1b[5;R", 5, 1, true},
+		{"missing row", "\x1b[;7R", 1, 7, true},
+		{"row only", "\x1b[9R", 9, 1, true},
+		{"zero defaults to one", "\x1b[0;0R", 1, 1, true},
+		{"too many params", "\x1b[1;2;3R", 0, 0, false},
+		{"non-digit", "\x1b[1a;2R", 0, 0, false},
+		{"sign", "\x1b[+1;2R", 0, 0, false},
+		{"missing ESC", "[12;40R", 0, 0, false},
+		{"wrong final byte", "\x1b[12;40H", 0, 0, false},
+		{"empty", "", 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := KeyEvent{Key: CPRResponse, RawBytes: []byte(tc.raw)}
+			row, col, ok := ParseCPR(event)
+			if ok != tc.ok {
+				t.Fatalf("Expected ok=%v for %q, got %v", tc.ok, tc.raw, ok)
+			}
+			if row != tc.row || col != tc.col {
+				t.Errorf("Expected (%d, %d) for %q, got (%d, %d)", tc.row, tc.col, tc.raw, row, col)
+			}
+		})
+	}
+}
+
+func TestKeyParserHealthCheck(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	if err := parser.HealthCheck(); err != nil {
+		t.Errorf("Expected fresh parser to pass health check, got: %v", err)
+	}
+
+	if err := parser.Close(); err != nil {
+		t.Fatalf("Failed to close parser: %v", err)
+	}
+
+	if err := parser.HealthCheck(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from closed parser, got: %v", err)
+	}
+
+	if _, err := parser.Feed([]byte{0x03}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Feed on closed parser, got: %v", err)
+	}
+}
+
+// TestKeyParserHealthCheckConcurrentClose is meant to be run with -race.
+func TestKeyParserHealthCheckConcurrentClose(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		for {
+			if err := parser.HealthCheck(); err != nil {
+				if errors.Is(err, ErrClosed) {
+					err = nil
+				}
+				done <- err
+				return
+			}
+		}
+	}()
+
+	if err := parser.Close(); err != nil {
+		t.Fatalf("Failed to close parser: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected health checks to pass or report ErrClosed, got: %v", err)
+	}
+}
+
+func TestPasteContent(t *testing.T) {
+	text := "stored text"
+	testCases := []struct {
+		name     string
+		event    KeyEvent
+		expected string
+		wantErr  bool
+	}{
+		{"text from parser", KeyEvent{Key: BracketedPaste, RawBytes: []byte(text), Text: &text}, text, false},
+		{"raw without markers", KeyEvent{Key: BracketedPaste, RawBytes: []byte("a\nb")}, "a\nb", false},
+		{"raw with markers", KeyEvent{Key: BracketedPaste, RawBytes: []byte("\x1b[200~a\x1b[Ab\x1b[201~")}, "a\x1b[Ab", false},
+		{"invalid UTF-8", KeyEvent{Key: BracketedPaste, RawBytes: []byte{0xff, 0xfe}}, "", true},
+		{"not a paste", KeyEvent{Key: Enter, RawBytes: []byte{0x0d}}, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := PasteContent(tc.event)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got content %q", content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if content != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+}
+
+func TestBracketedPasteAcrossFeeds(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	// The paste contains newlines and an Up arrow sequence that must not be
+	// interpreted as keys, and is split across several reads
+	chunks := []string{
+		"\x1b[200~first line\n",
+		"second \x1b[A line\r\nthird",
+		" line\x1b[20",
+		"1~",
+	}
+
+	var events []KeyEvent
+	for _, chunk := range chunks {
+		chunkEvents, err := parser.FeedString(chunk)
+		if err != nil {
+			t.Fatalf("Failed to feed chunk %q: %v", chunk, err)
+		}
+		events = append(events, chunkEvents...)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected a single paste event, got %d: %+v", len(events), events)
+	}
+
+	content, err := PasteContent(events[0])
+	if err != nil {
+		t.Fatalf("Failed to extract paste content: %v", err)
+	}
+	expected := "first line\nsecond \x1b[A line\r\nthird line"
+	if content != expected {
+		t.Errorf("Expected %q, got %q", expected, content)
+	}
+}
+
+func TestIncrementalBracketedPaste(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	if err := parser.SetIncrementalPaste(true); err != nil {
+		t.Fatalf("Failed to enable incremental paste: %v", err)
+	}
+
+	reads := []string{
+		"\x1b[200~first line\n",
+		"second line\nthird",
+		" line\x1b[201~",
+	}
+
+	var chunks []KeyEvent
+	for i, read := range reads {
+		events, err := parser.FeedString(read)
+		if err != nil {
+			t.Fatalf("Failed to feed read %q: %v", read, err)
+		}
+		if i < len(reads)-1 && len(events) == 0 {
+			t.Errorf("Expected an interim chunk after read %d", i)
+		}
+		chunks = append(chunks, events...)
+	}
+
+	var content strings.Builder
+	for i, event := range chunks {
+		if event.Key != BracketedPaste {
+			t.Fatalf("Expected BracketedPaste chunk, got: %v", event.Key)
+		}
+		if last := i == len(chunks)-1; event.Incomplete == last {
+			t.Errorf("Chunk %d: expected Incomplete=%v, got: %v", i, !last, event.Incomplete)
+		}
+		text, err := PasteContent(event)
+		if err != nil {
+			t.Fatalf("Failed to extract paste content: %v", err)
+		}
+		content.WriteString(text)
+	}
+
+	expected := "first line\nsecond line\nthird line"
+	if content.String() != expected {
+		t.Errorf("Expected reassembled paste %q, got %q", expected, content.String())
+	}
+}
+
+func TestKeyParserEvents(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	r, w := io.Pipe()
+	events := parser.Events(ctx, r)
+
+	go func() {
+		w.Write([]byte("a\x1b["))
+		w.Write([]byte("A"))
+		// A dangling escape is only emitted by the flush on EOF
+		w.Write([]byte("\x1b"))
+		w.Close()
+	}()
+
+	var keys []Key
+	for event := range events {
+		keys = append(keys, event.Key)
+	}
+
+	expected := []Key{NotDefined, Up, Escape}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got: %v", expected, keys)
+	}
+}
+
+func TestKeyParserEventsCancel(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, w := io.Pipe()
+	defer w.Close()
+	events := parser.Events(ctx, r)
+
+	cancel()
+	// Unblock the pending Read so the goroutine observes the cancellation
+	go w.Write([]byte("x"))
+
+	for event := range events {
+		t.Errorf("Expected no events after cancellation, got: %v", event.Key)
+	}
+}
+
+// TestKeyParserConcurrentFeed is meant to be run with -race.
+func TestKeyParserConcurrentFeed(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	const goroutines, feeds = 8, 200
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < feeds; j++ {
+				// Each Feed holds a complete sequence, so it decodes on its own
+				events, err := parser.Feed([]byte("\x1b[A"))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(events) != 1 || events[0].Key != Up {
+					errs <- fmt.Errorf("goroutine %d: expected a single Up event, got: %v", i, events)
+					return
+				}
+				if j%10 == 0 {
+					if _, err := parser.Flush(); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestKeyParserAllocationsReleased checks that memory allocated through the
+// exported malloc is reused after the exported free.
+func TestKeyParserAllocationsReleased(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	text := strings.Repeat("x", 1024)
+	roundTrip := func() {
+		ptr, err := parser.allocateString(text)
+		if err != nil {
+			t.Fatalf("Failed to allocate string: %v", err)
+		}
+		parser.freeMemory(ptr)
+	}
+
+	roundTrip()
+	memorySize := parser.module.Memory().Size()
+	heapNext := parser.heaps.heaps[parser.module].next
+
+	for i := 0; i < 10000; i++ {
+		roundTrip()
+	}
+
+	if got := parser.module.Memory().Size(); got != memorySize {
+		t.Errorf("Expected guest memory to stay at %d bytes, got: %d", memorySize, got)
+	}
+	if got := parser.heaps.heaps[parser.module].next; got != heapNext {
+		t.Errorf("Expected heap to stay at offset %d, got: %d", heapNext, got)
+	}
+}
+
+func TestKeyParserFeedReader(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	// Sequences straddle chunk boundaries, the last chunk arrives with
+	// io.EOF and the trailing escape is only emitted by the final flush
+	input := strings.Repeat("\x1b[A", feedReaderChunkSize/3) + "\x1b[B\x1b"
+	r := iotest.DataErrReader(bytes.NewReader([]byte(input)))
+
+	events, err := parser.FeedReader(r)
+	if err != nil {
+		t.Fatalf("Failed to feed reader: %v", err)
+	}
+
+	if len(events) != feedReaderChunkSize/3+2 {
+		t.Fatalf("Expected %d events, got: %d", feedReaderChunkSize/3+2, len(events))
+	}
+	for i, event := range events[:len(events)-2] {
+		if event.Key != Up {
+			t.Fatalf("Event %d: expected Up, got: %v", i, event.Key)
+		}
+	}
+	if events[len(events)-2].Key != Down || events[len(events)-1].Key != Escape {
+		t.Errorf("Expected Down, Escape at the end, got: %v, %v", events[len(events)-2].Key, events[len(events)-1].Key)
+	}
+}
+
+func TestKeyParserFeedReaderError(t *testing.T) {
+	parser, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	readErr := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("\x1b[A"), iotest.ErrReader(readErr))
+
+	events, err := parser.FeedReader(r)
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got: %v", err)
+	}
+	if len(events) != 1 || events[0].Key != Up {
+		t.Errorf("Expected the events decoded before the error, got: %v", events)
+	}
+}
+
+func TestCoalesceTextEvents(t *testing.T) {
+	text := func(s string) KeyEvent {
+		return KeyEvent{Key: NotDefined, RawBytes: []byte(s), Text: &s}
+	}
+
+	events := []KeyEvent{
+		text("a"), text("b"), text("c"),
+		{Key: ControlA, RawBytes: []byte{0x01}},
+		text("d"), text("é"),
+		{Key: NotDefined, RawBytes: []byte("\x1b[99~")},
+		text("f"),
+	}
+
+	got := coalesceTextEvents(events)
+	expected := []KeyEvent{
+		text("abc"),
+		{Key: ControlA, RawBytes: []byte{0x01}},
+		text("dé"),
+		{Key: NotDefined, RawBytes: []byte("\x1b[99~")},
+		text("f"),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, got)
+	}
+
+	// The input slice must not be modified
+	if *events[0].Text != "a" || string(events[0].RawBytes) != "a" {
+		t.Errorf("Expected input events to be unchanged, got: %+v", events[0])
+	}
+}
+
+func TestKeyParserCoalesceText(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	parser.SetCoalesceText(true)
+
+	events, err := parser.FeedString("abc")
+	if err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 coalesced event, got: %d", len(events))
+	}
+	if events[0].Key != NotDefined || events[0].Text == nil || *events[0].Text != "abc" {
+		t.Errorf("Expected NotDefined event with text \"abc\", got: %+v", events[0])
+	}
+
+	// Separate reads are never merged
+	first, err := parser.FeedString("x")
+	if err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+	second, err := parser.FeedString("y")
+	if err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+	if len(first) != 1 || len(second) != 1 {
+		t.Errorf("Expected one event per read, got: %d and %d", len(first), len(second))
+	}
+}
@@ -0,0 +1,139 @@
+package keyparsing
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// defaultKillRingSize is the number of entries a KillRing keeps by default
+const defaultKillRingSize = 60
+
+// KillRing holds recently killed text for Buffer.Yank and Buffer.YankPop,
+// like readline's kill ring. One ring can be shared by several buffers so
+// text killed in one can be yanked into another. It is not safe for
+// concurrent use.
+type KillRing struct {
+	entries []string // Oldest first
+	size    int
+}
+
+// NewKillRing creates a kill ring that keeps at most size entries, dropping
+// the oldest when full. A size of zero or less selects the default of 60.
+func NewKillRing(size int) *KillRing {
+	if size <= 0 {
+		size = defaultKillRingSize
+	}
+	return &KillRing{size: size}
+}
+
+// Push adds text as the most recent entry. Empty text is ignored.
+func (r *KillRing) Push(text string) {
+	if text == "" {
+		return
+	}
+	if len(r.entries) == r.size {
+		r.entries = append(r.entries[:0], r.entries[1:]...)
+	}
+	r.entries = append(r.entries, text)
+}
+
+// Len returns the number of entries in the ring.
+func (r *KillRing) Len() int {
+	return len(r.entries)
+}
+
+// Latest returns the most recent entry, or false if the ring is empty.
+func (r *KillRing) Latest() (string, bool) {
+	if len(r.entries) == 0 {
+		return "", false
+	}
+	return r.entries[len(r.entries)-1], true
+}
+
+// SetKillRing attaches ring to the buffer, or detaches it when ring is nil.
+// While attached, DeleteWordBeforeCursor, DeleteWordAfterCursor,
+// DeleteToLineEnd and DeleteToLineStart push the text they delete onto it.
+// Single-character deletions do not.
+func (b *Buffer) SetKillRing(ring *KillRing) {
+	b.killRing = ring
+	b.yanked = false
+}
+
+// kill pushes the text deleted by a kill operation onto the kill ring. It
+// passes the operation's results through for use in return statements.
+func (b *Buffer) kill(text string, err error) (string, error) {
+	if err == nil && b.killRing != nil {
+		b.killRing.Push(text)
+	}
+	return text, err
+}
+
+// Yank inserts the most recent kill ring entry at the cursor (Ctrl+Y) and
+// moves the cursor past it. It does nothing when no ring is attached or the
+// ring is empty.
+func (b *Buffer) Yank() error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	b.yanked = false
+	if b.killRing == nil || b.killRing.Len() == 0 {
+		return nil
+	}
+
+	index := b.killRing.Len() - 1
+	if err := b.InsertText(b.killRing.entries[index], false, true); err != nil {
+		return err
+	}
+	return b.rememberYank(index)
+}
+
+// YankPop replaces the text inserted by the immediately preceding Yank or
+// YankPop with the next older kill ring entry (Alt+Y), cycling back to the
+// most recent entry after the oldest. The replacement is a single undo step.
+// It returns an error if the buffer has changed since the last yank.
+func (b *Buffer) YankPop() error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+	if !b.yanked || b.killRing == nil || b.killRing.Len() == 0 {
+		return fmt.Errorf("yank pop must follow a yank")
+	}
+
+	chars, pos, err := b.textAndCursor()
+	if err != nil {
+		return err
+	}
+	if pos != b.yankCursor || string(chars) != b.yankText {
+		b.yanked = false
+		return fmt.Errorf("yank pop must follow a yank")
+	}
+
+	// The ring may have changed size through another buffer in the meantime
+	n := b.killRing.Len()
+	index := (b.yankIndex - 1 + n) % n
+	err = b.ApplyEdits([]BufferEdit{
+		{Op: EditDeleteBeforeCursor, Count: b.yankLen},
+		{Op: EditInsert, Text: b.killRing.entries[index]},
+	})
+	if err != nil {
+		return err
+	}
+	return b.rememberYank(index)
+}
+
+// rememberYank records the state after inserting kill ring entry index so a
+// following YankPop can verify nothing else changed the buffer.
+func (b *Buffer) rememberYank(index int) error {
+	chars, pos, err := b.textAndCursor()
+	if err != nil {
+		return err
+	}
+
+	b.yanked = true
+	b.yankIndex = index
+	b.yankLen = utf8.RuneCountInString(b.killRing.entries[index])
+	b.yankText = string(chars)
+	b.yankCursor = pos
+	return nil
+}
@@ -0,0 +1,163 @@
+package keyparsing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestKillRing(t *testing.T) {
+	ring := NewKillRing(3)
+	if _, ok := ring.Latest(); ok {
+		t.Error("Expected an empty ring to have no latest entry")
+	}
+
+	for _, text := range []string{"one", "", "two", "three", "four"} {
+		ring.Push(text)
+	}
+
+	// Empty text is ignored and the oldest entry is dropped when full
+	if !reflect.DeepEqual(ring.entries, []string{"two", "three", "four"}) {
+		t.Errorf("Expected entries [two three four], got: %v", ring.entries)
+	}
+	if latest, ok := ring.Latest(); !ok || latest != "four" {
+		t.Errorf("Expected latest %q, got: %q", "four", latest)
+	}
+
+	if ring := NewKillRing(0); ring.size != defaultKillRingSize {
+		t.Errorf("Expected default size %d, got: %d", defaultKillRingSize, ring.size)
+	}
+}
+
+func TestBufferKillAndYank(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+
+	ring := NewKillRing(0)
+	buffer.SetKillRing(ring)
+
+	buffer.SetText("git commit -m message")
+	buffer.SetCursorPosition(10)
+	buffer.DeleteToLineEnd()        // " -m message"
+	buffer.DeleteWordBeforeCursor() // "commit"
+	buffer.DeleteBeforeCursor(1)    // Not a kill
+	buffer.DeleteToLineStart()      // "git"
+
+	expected := []string{" -m message", "commit", "git"}
+	if !reflect.DeepEqual(ring.entries, expected) {
+		t.Fatalf("Expected kill ring %q, got: %q", expected, ring.entries)
+	}
+
+	assertText := func(expected string) {
+		t.Helper()
+		text, _ := buffer.Text()
+		pos, _ := buffer.CursorPosition()
+		if text != expected || pos != len([]rune(expected)) {
+			t.Errorf("Expected %q with cursor at the end, got: %q, cursor %d", expected, text, pos)
+		}
+	}
+
+	// Yank inserts the latest kill, YankPop cycles through older ones
+	if err := buffer.Yank(); err != nil {
+		t.Fatalf("Failed to yank: %v", err)
+	}
+	assertText("git")
+	for _, want := range []string{"commit", " -m message", "git"} {
+		if err := buffer.YankPop(); err != nil {
+			t.Fatalf("Failed to yank pop: %v", err)
+		}
+		assertText(want)
+	}
+
+	// Undo removes a yank pop as one step
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertText(" -m message")
+
+	// YankPop is refused once the buffer has changed after the yank
+	buffer.Yank()
+	buffer.InsertText("!", false, true)
+	if err := buffer.YankPop(); err == nil {
+		t.Error("Expected YankPop to fail after an edit")
+	}
+	assertText(" -m messagegit!")
+}
+
+func TestBufferYankWithoutRing(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	if err := buffer.Yank(); err != nil {
+		t.Errorf("Expected Yank without a ring to be a no-op, got: %v", err)
+	}
+	if err := buffer.YankPop(); err == nil {
+		t.Error("Expected YankPop without a yank to fail")
+	}
+}
+
+func TestBufferCopySharesKillRing(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	original, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer original.Close()
+
+	ring := NewKillRing(0)
+	original.SetKillRing(ring)
+	original.SetText("hello world")
+
+	copied, err := original.Copy()
+	if err != nil {
+		t.Fatalf("Failed to copy buffer: %v", err)
+	}
+	defer copied.Close()
+
+	if _, err := copied.DeleteToLineEnd(); err != nil {
+		t.Fatalf("Failed to kill line: %v", err)
+	}
+	if latest, ok := ring.Latest(); !ok || latest != "hello world" {
+		t.Errorf("Expected the copy to kill into the shared ring, got: %q, %v", latest, ok)
+	}
+
+	// The original can yank what the copy killed
+	original.SetCursorPosition(11)
+	if err := original.Yank(); err != nil {
+		t.Fatalf("Failed to yank: %v", err)
+	}
+	if text, _ := original.Text(); text != "hello worldhello world" {
+		t.Errorf("Expected %q, got: %q", "hello worldhello world", text)
+	}
+	if err := copied.YankPop(); err == nil {
+		t.Error("Expected YankPop on the copy to fail before it yanks")
+	}
+}
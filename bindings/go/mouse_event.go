@@ -0,0 +1,138 @@
+package keyparsing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MouseButton identifies the button reported by a VT100 mouse event.
+type MouseButton int
+
+const (
+	MouseLeft MouseButton = iota
+	MouseMiddle
+	MouseRight
+	// MouseNone is reported for X10 releases (which do not say which button
+	// was released) and for motion events without a pressed button.
+	MouseNone
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+)
+
+// MouseEvent is a decoded Vt100MouseEvent.
+type MouseEvent struct {
+	Button MouseButton
+	// X and Y are 1-based terminal column and row.
+	X int
+	Y int
+	// Pressed is true for presses and wheel events, false for releases.
+	Pressed bool
+	// Motion is true when the event was generated by mouse movement (drag).
+	Motion bool
+	Shift  bool
+	Alt    bool
+	Ctrl   bool
+}
+
+// Button code bits shared by the X10 and SGR encodings
+const (
+	mouseButtonMask = 0x03
+	mouseShiftBit   = 0x04
+	mouseAltBit     = 0x08
+	mouseCtrlBit    = 0x10
+	mouseMotionBit  = 0x20
+	mouseWheelBit   = 0x40
+)
+
+// DecodeMouseEvent decodes the raw bytes of a Vt100MouseEvent key event.
+// Both the legacy X10 encoding (ESC [ M Cb Cx Cy) and the SGR encoding
+// (ESC [ < b ; x ; y M/m) are supported. In SGR mode a trailing 'm'
+// marks a release; X10 reports releases with button code 3.
+func DecodeMouseEvent(event KeyEvent) (*MouseEvent, error) {
+	raw := event.RawBytes
+
+	switch {
+	case len(raw) >= 3 && string(raw[:3]) == "\x1b[<":
+		return decodeSGRMouse(raw)
+	case len(raw) >= 3 && string(raw[:3]) == "\x1b[M":
+		return decodeX10Mouse(raw)
+	default:
+		return nil, fmt.Errorf("not a mouse event sequence: %q", raw)
+	}
+}
+
+func decodeX10Mouse(raw []byte) (*MouseEvent, error) {
+	if len(raw) != 6 {
+		return nil, fmt.Errorf("invalid X10 mouse sequence length %d: %q", len(raw), raw)
+	}
+	if raw[3] < 32 || raw[4] < 33 || raw[5] < 33 {
+		return nil, fmt.Errorf("invalid X10 mouse sequence: %q", raw)
+	}
+
+	return newMouseEvent(int(raw[3])-32, int(raw[4])-32, int(raw[5])-32), nil
+}
+
+func decodeSGRMouse(raw []byte) (*MouseEvent, error) {
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("invalid SGR mouse sequence: %q", raw)
+	}
+	final := raw[len(raw)-1]
+	if final != 'M' && final != 'm' {
+		return nil, fmt.Errorf("invalid SGR mouse sequence: %q", raw)
+	}
+
+	params := strings.Split(string(raw[3:len(raw)-1]), ";")
+	if len(params) != 3 {
+		return nil, fmt.Errorf("invalid SGR mouse sequence: %q", raw)
+	}
+
+	var values [3]int
+	for i, param := range params {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid SGR mouse parameter %q in %q", param, raw)
+		}
+		values[i] = n
+	}
+
+	event := newMouseEvent(values[0], values[1], values[2])
+	if final == 'm' {
+		event.Pressed = false
+	}
+	return event, nil
+}
+
+// newMouseEvent builds an event from a button code and coordinates.
+// Button code 3 means no button is held, so such events are never pressed.
+func newMouseEvent(code, x, y int) *MouseEvent {
+	event := &MouseEvent{
+		X:       x,
+		Y:       y,
+		Pressed: true,
+		Motion:  code&mouseMotionBit != 0,
+		Shift:   code&mouseShiftBit != 0,
+		Alt:     code&mouseAltBit != 0,
+		Ctrl:    code&mouseCtrlBit != 0,
+	}
+
+	button := code & mouseButtonMask
+	switch {
+	case code&mouseWheelBit != 0 && button == 0:
+		event.Button = MouseWheelUp
+	case code&mouseWheelBit != 0 && button == 1:
+		event.Button = MouseWheelDown
+	case code&mouseWheelBit != 0 && button == 2:
+		event.Button = MouseWheelLeft
+	case code&mouseWheelBit != 0 && button == 3:
+		event.Button = MouseWheelRight
+	case button == 3:
+		event.Button = MouseNone
+		event.Pressed = false
+	default:
+		event.Button = MouseButton(button)
+	}
+	return event
+}
@@ -0,0 +1,112 @@
+package keyparsing
+
+import (
+	"testing"
+)
+
+func TestDecodeMouseEvent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		expected MouseEvent
+	}{
+		{
+			name:     "X10 left press",
+			raw:      "\x1b[M" + string([]byte{32, 33 + 9, 33 + 4}),
+			expected: MouseEvent{Button: MouseLeft, X: 10, Y: 5, Pressed: true},
+		},
+		{
+			name:     "X10 release",
+			raw:      "\x1b[M" + string([]byte{32 + 3, 33, 33}),
+			expected: MouseEvent{Button: MouseNone, X: 1, Y: 1, Pressed: false},
+		},
+		{
+			name:     "X10 wheel up",
+			raw:      "\x1b[M" + string([]byte{32 + 64, 33 + 2, 33 + 2}),
+			expected: MouseEvent{Button: MouseWheelUp, X: 3, Y: 3, Pressed: true},
+		},
+		{
+			name:     "X10 wheel down",
+			raw:      "\x1b[M" + string([]byte{32 + 65, 33 + 2, 33 + 2}),
+			expected: MouseEvent{Button: MouseWheelDown, X: 3, Y: 3, Pressed: true},
+		},
+		{
+			name:     "X10 ctrl right press",
+			raw:      "\x1b[M" + string([]byte{32 + 2 + 16, 33, 33}),
+			expected: MouseEvent{Button: MouseRight, X: 1, Y: 1, Pressed: true, Ctrl: true},
+		},
+		{
+			name:     "SGR left press",
+			raw:      "\x1b[<0;12;40M",
+			expected: MouseEvent{Button: MouseLeft, X: 12, Y: 40, Pressed: true},
+		},
+		{
+			name:     "SGR left release",
+			raw:      "\x1b[<0;12;40m",
+			expected: MouseEvent{Button: MouseLeft, X: 12, Y: 40, Pressed: false},
+		},
+		{
+			name:     "SGR middle press with shift and alt",
+			raw:      "\x1b[<13;1;2M",
+			expected: MouseEvent{Button: MouseMiddle, X: 1, Y: 2, Pressed: true, Shift: true, Alt: true},
+		},
+		{
+			name:     "SGR wheel up",
+			raw:      "\x1b[<64;5;6M",
+			expected: MouseEvent{Button: MouseWheelUp, X: 5, Y: 6, Pressed: true},
+		},
+		{
+			name:     "SGR wheel down",
+			raw:      "\x1b[<65;5;6M",
+			expected: MouseEvent{Button: MouseWheelDown, X: 5, Y: 6, Pressed: true},
+		},
+		{
+			name:     "SGR wheel right",
+			raw:      "\x1b[<67;5;6M",
+			expected: MouseEvent{Button: MouseWheelRight, X: 5, Y: 6, Pressed: true},
+		},
+		{
+			name:     "SGR left drag",
+			raw:      "\x1b[<32;300;200M",
+			expected: MouseEvent{Button: MouseLeft, X: 300, Y: 200, Pressed: true, Motion: true},
+		},
+		{
+			name:     "SGR hover without button",
+			raw:      "\x1b[<35;7;8M",
+			expected: MouseEvent{Button: MouseNone, X: 7, Y: 8, Pressed: false, Motion: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := KeyEvent{Key: Vt100MouseEvent, RawBytes: []byte(tc.raw)}
+			decoded, err := DecodeMouseEvent(event)
+			if err != nil {
+				t.Fatalf("Failed to decode %q: %v", tc.raw, err)
+			}
+			if *decoded != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, *decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeMouseEventInvalid(t *testing.T) {
+	testCases := []string{
+		"",
+		"\x1b[A",
+		"\x1b[M !",
+		"\x1b[<0;1M",
+		"\x1b[<0;1;2;3M",
+		"\x1b[<0;a;2M",
+		"\x1b[<0;1;2X",
+		"\x1b[<",
+	}
+
+	for _, raw := range testCases {
+		event := KeyEvent{Key: Vt100MouseEvent, RawBytes: []byte(raw)}
+		if decoded, err := DecodeMouseEvent(event); err == nil {
+			t.Errorf("Expected error decoding %q, got %+v", raw, decoded)
+		}
+	}
+}
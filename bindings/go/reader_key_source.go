@@ -0,0 +1,93 @@
+package keyparsing
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ReaderKeySource decodes key events from an io.Reader one at a time, for
+// replaying recorded sessions or scripting input in tests without a terminal.
+// Unlike KeyParser.Events it runs no goroutine: bytes are read only when
+// ReadKey has no decoded events left to return.
+type ReaderKeySource struct {
+	ctx     context.Context
+	parser  *KeyParser
+	r       io.Reader
+	buf     []byte
+	pending []KeyEvent
+	err     error // Reported once pending events are drained
+}
+
+// NewReaderKeySource creates a ReaderKeySource reading from r. wasm is the
+// parser binary as accepted by NewKeyParser; nil selects the embedded one.
+// The source owns its parser and must be closed with Close.
+func NewReaderKeySource(ctx context.Context, r io.Reader, wasm []byte) (*ReaderKeySource, error) {
+	if r == nil {
+		return nil, fmt.Errorf("reader is nil")
+	}
+	if wasm == nil {
+		wasm = embeddedWasm
+	}
+
+	parser, err := NewKeyParser(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key parser: %w", err)
+	}
+
+	return &ReaderKeySource{
+		ctx:    ctx,
+		parser: parser,
+		r:      r,
+		buf:    make([]byte, 1024),
+	}, nil
+}
+
+// ReadKey returns the next decoded key event, reading more input as needed.
+// When the reader reports io.EOF, any partial sequence left in the parser
+// (such as a trailing lone escape) is flushed and returned first; after the
+// last event ReadKey returns io.EOF. Other read errors and context
+// cancellation are returned the same way, after already decoded events.
+func (s *ReaderKeySource) ReadKey() (KeyEvent, error) {
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return KeyEvent{}, s.err
+		}
+		if err := s.ctx.Err(); err != nil {
+			s.err = err
+			continue
+		}
+
+		n, err := s.r.Read(s.buf)
+		if n > 0 {
+			events, feedErr := s.parser.Feed(s.buf[:n])
+			if feedErr != nil {
+				s.err = fmt.Errorf("failed to parse input: %w", feedErr)
+				continue
+			}
+			s.pending = append(s.pending, events...)
+		}
+
+		switch {
+		case err == io.EOF:
+			if events, flushErr := s.parser.Flush(); flushErr == nil {
+				s.pending = append(s.pending, events...)
+			}
+			s.err = io.EOF
+		case err != nil:
+			s.err = err
+		}
+	}
+
+	event := s.pending[0]
+	s.pending = s.pending[1:]
+	return event, nil
+}
+
+// Close releases the parser. It does not close the underlying reader.
+func (s *ReaderKeySource) Close() error {
+	if s == nil || s.parser == nil {
+		return nil
+	}
+	return s.parser.Close()
+}
@@ -0,0 +1,62 @@
+package keyparsing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderKeySource(t *testing.T) {
+	// Deliver one byte per Read so sequences are split across reads
+	input := []byte("a\x1b[A\r\x1b[B\x1b")
+	source, err := NewReaderKeySource(context.Background(), iotest.OneByteReader(bytes.NewReader(input)), nil)
+	if err != nil {
+		t.Fatalf("Failed to create key source: %v", err)
+	}
+	defer source.Close()
+
+	expected := []Key{NotDefined, Up, Enter, Down, Escape}
+	for i, key := range expected {
+		event, err := source.ReadKey()
+		if err != nil {
+			t.Fatalf("Event %d: failed to read key: %v", i, err)
+		}
+		if event.Key != key {
+			t.Errorf("Event %d: expected %v, got: %v", i, key, event.Key)
+		}
+	}
+
+	// EOF is sticky once every event has been returned
+	for i := 0; i < 2; i++ {
+		if _, err := source.ReadKey(); !errors.Is(err, io.EOF) {
+			t.Fatalf("Expected io.EOF, got: %v", err)
+		}
+	}
+}
+
+func TestReaderKeySourceReadError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	r := io.MultiReader(bytes.NewReader([]byte("x")), iotest.ErrReader(readErr))
+
+	source, err := NewReaderKeySource(context.Background(), r, nil)
+	if err != nil {
+		t.Fatalf("Failed to create key source: %v", err)
+	}
+	defer source.Close()
+
+	if event, err := source.ReadKey(); err != nil || event.Key != NotDefined {
+		t.Fatalf("Expected the decoded key before the error, got: %v, %v", event.Key, err)
+	}
+	if _, err := source.ReadKey(); !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got: %v", err)
+	}
+}
+
+func TestNewReaderKeySourceNilReader(t *testing.T) {
+	if _, err := NewReaderKeySource(context.Background(), nil, nil); err == nil {
+		t.Error("Expected error for nil reader")
+	}
+}
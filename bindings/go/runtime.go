@@ -0,0 +1,149 @@
+package keyparsing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Runtime compiles the replkit WASM module once and creates parsers that
+// share the compiled code. Each parser still gets its own module instance,
+// so parsers (and the buffers and documents they create) stay isolated from
+// each other. Creating many parsers from one Runtime avoids recompiling the
+// module for every NewKeyParser call.
+//
+// Example usage:
+//
+//	rt, err := keyparsing.NewRuntime(ctx, wasmBytes)
+//	if err != nil {
+//	    return err
+//	}
+//	defer rt.Close()
+//
+//	parser, err := rt.NewParser()
+//	if err != nil {
+//	    return err
+//	}
+//	defer parser.Close()
+type Runtime struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	heaps    *guestHeaps
+	ctx      context.Context
+}
+
+// NewRuntime compiles wasmBytes; nil selects the embedded WASM binary.
+func NewRuntime(ctx context.Context, wasmBytes []byte) (*Runtime, error) {
+	if wasmBytes == nil {
+		wasmBytes = embeddedWasm
+	}
+	if len(wasmBytes) == 0 {
+		return nil, fmt.Errorf("WASM binary cannot be empty")
+	}
+
+	// Create a new WASM runtime
+	runtime := wazero.NewRuntime(ctx)
+
+	// Instantiate WASI to support basic system calls
+	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	// Create env module for WASM malloc/free functions
+	heaps := newGuestHeaps()
+	if err := heaps.instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate env module: %w", err)
+	}
+
+	// Compile the WASM module once for all parsers
+	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile WASM module: %w", err)
+	}
+
+	return &Runtime{runtime: runtime, compiled: compiled, heaps: heaps, ctx: ctx}, nil
+}
+
+// NewParser creates a KeyParser in a new instance of the compiled module.
+// Closing the parser releases only that instance; the Runtime must outlive
+// the parsers created from it.
+func (r *Runtime) NewParser() (*KeyParser, error) {
+	if r == nil || r.runtime == nil {
+		return nil, fmt.Errorf("runtime is nil or closed")
+	}
+	ctx := r.ctx
+
+	// Anonymous instances so the same module can be instantiated repeatedly
+	module, err := r.runtime.InstantiateModule(ctx, r.compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate WASM module: %w", err)
+	}
+
+	// Get function handles
+	newParserFn := module.ExportedFunction("new_parser")
+	if newParserFn == nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("WASM module does not export 'new_parser' function")
+	}
+
+	feedFn := module.ExportedFunction("feed")
+	if feedFn == nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("WASM module does not export 'feed' function")
+	}
+
+	flushFn := module.ExportedFunction("flush")
+	if flushFn == nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("WASM module does not export 'flush' function")
+	}
+
+	resetFn := module.ExportedFunction("reset")
+	if resetFn == nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("WASM module does not export 'reset' function")
+	}
+
+	destroyFn := module.ExportedFunction("destroy_parser")
+	if destroyFn == nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("WASM module does not export 'destroy_parser' function")
+	}
+
+	// Create a new parser instance in WASM
+	results, err := newParserFn.Call(ctx)
+	if err != nil {
+		module.Close(ctx)
+		return nil, fmt.Errorf("failed to create parser instance: %w", err)
+	}
+
+	return &KeyParser{
+		module:      module,
+		heaps:       r.heaps,
+		ctx:         ctx,
+		newParserFn: newParserFn,
+		feedFn:      feedFn,
+		flushFn:     flushFn,
+		resetFn:     resetFn,
+		destroyFn:   destroyFn,
+		parserID:    uint32(results[0]),
+	}, nil
+}
+
+// Close releases the compiled module and every instance created from it.
+// Parsers created by NewParser must not be used afterwards.
+func (r *Runtime) Close() error {
+	if r == nil || r.runtime == nil {
+		return nil // Already closed
+	}
+	err := r.runtime.Close(r.ctx)
+	r.runtime = nil
+	r.compiled = nil
+	return err
+}
@@ -0,0 +1,105 @@
+package keyparsing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewRuntimeEmptyBinary(t *testing.T) {
+	if _, err := NewRuntime(context.Background(), []byte{}); err == nil {
+		t.Error("Expected error when creating runtime with empty WASM binary")
+	}
+}
+
+func TestRuntimeParsersAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	rt, err := NewRuntime(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer rt.Close()
+
+	first, err := rt.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	second, err := rt.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer second.Close()
+
+	// A partial sequence in one parser must not leak into the other
+	if _, err := first.Feed([]byte("\x1b[")); err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+	events, err := second.Feed([]byte("A"))
+	if err != nil {
+		t.Fatalf("Failed to feed input: %v", err)
+	}
+	if len(events) != 1 || events[0].Key != NotDefined {
+		t.Errorf("Expected a single plain key, got: %v", events)
+	}
+
+	// Closing one parser leaves the runtime and its other parsers usable
+	if err := first.Close(); err != nil {
+		t.Fatalf("Failed to close parser: %v", err)
+	}
+	if _, err := second.Feed([]byte("\x1b[B")); err != nil {
+		t.Errorf("Expected second parser to keep working, got: %v", err)
+	}
+	third, err := rt.NewParser()
+	if err != nil {
+		t.Fatalf("Failed to create parser after close: %v", err)
+	}
+	third.Close()
+}
+
+func TestRuntimeClose(t *testing.T) {
+	ctx := context.Background()
+	rt, err := NewRuntime(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to create runtime: %v", err)
+	}
+
+	if err := rt.Close(); err != nil {
+		t.Errorf("Failed to close runtime: %v", err)
+	}
+	if err := rt.Close(); err != nil {
+		t.Errorf("Expected second Close to be a no-op, got: %v", err)
+	}
+	if _, err := rt.NewParser(); err == nil {
+		t.Error("Expected error when creating parser from closed runtime")
+	}
+}
+
+// Benchmark tests
+
+func BenchmarkNewKeyParser(b *testing.B) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		parser, err := NewKeyParser(ctx, embeddedWasm)
+		if err != nil {
+			b.Fatalf("Failed to create parser: %v", err)
+		}
+		parser.Close()
+	}
+}
+
+func BenchmarkRuntimeNewParser(b *testing.B) {
+	ctx := context.Background()
+	rt, err := NewRuntime(ctx, nil)
+	if err != nil {
+		b.Fatalf("Failed to create runtime: %v", err)
+	}
+	defer rt.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser, err := rt.NewParser()
+		if err != nil {
+			b.Fatalf("Failed to create parser: %v", err)
+		}
+		parser.Close()
+	}
+}
@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package keyparsing
+
+import "golang.org/x/sys/unix"
+
+// getTermios reads the terminal settings of fd.
+func getTermios(fd int) (*unix.Termios, error) {
+	return unix.IoctlGetTermios(fd, unix.TIOCGETA)
+}
+
+// setTermios applies terminal settings to fd immediately.
+func setTermios(fd int, termios *unix.Termios) error {
+	return unix.IoctlSetTermios(fd, unix.TIOCSETA, termios)
+}
@@ -3,6 +3,8 @@ package keyparsing
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 // WasmBufferState represents the serializable state of a Buffer for WASM interop
@@ -25,8 +27,44 @@ type WasmDocumentState struct {
 type Buffer struct {
 	parser   *KeyParser
 	bufferID uint32
+
+	// Undo history, most recent state last. Nothing is recorded unless
+	// undoEnabled is set (see SetUndoEnabled).
+	undoEnabled    bool
+	undoStack      []*WasmBufferState
+	redoStack      []*WasmBufferState
+	undoCoalescing bool
+	// typingRun is set while consecutive single-character inserts share one undo
+	// step; typingEnd is the cursor position the last of them left behind.
+	typingRun bool
+	typingEnd int
+
+	// Selection anchor as a rune index, valid while hasAnchor is set
+	selectionAnchor int
+	hasAnchor       bool
+
+	// Word motions and deletions stop inside identifiers (see SetSubwordMode)
+	subwordMode bool
+
+	// Kill ring fed by the kill operations (see SetKillRing)
+	killRing *KillRing
+	// State left by the last Yank or YankPop: the ring entry inserted, its
+	// length in runes, and the text and cursor afterwards. yanked is cleared
+	// when YankPop finds the buffer changed since.
+	yanked     bool
+	yankIndex  int
+	yankLen    int
+	yankText   string
+	yankCursor int
 }
 
+// maxUndoStates bounds the undo history kept by a Buffer
+const maxUndoStates = 100
+
+// textLenNotFound is returned by buffer_text_len and document_text_len for an
+// unknown ID
+const textLenNotFound = 1<<32 - 1
+
 // NewBuffer creates a new Buffer instance using the existing KeyParser's WASM runtime
 func (p *KeyParser) NewBuffer() (*Buffer, error) {
 	if p == nil || p.module == nil {
@@ -66,6 +104,48 @@ func (b *Buffer) Text() (string, error) {
 	return doc.Text()
 }
 
+// ByteLen returns the length of the buffer text in UTF-8 bytes, as len(text) would,
+// without copying the text out of the WASM module
+func (b *Buffer) ByteLen() (int, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return 0, fmt.Errorf("buffer is nil or closed")
+	}
+
+	textLenFn := b.parser.module.ExportedFunction("buffer_text_len")
+	if textLenFn == nil {
+		return 0, fmt.Errorf("WASM module does not export 'buffer_text_len' function")
+	}
+
+	results, err := textLenFn.Call(b.parser.ctx, uint64(b.bufferID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get text length: %w", err)
+	}
+	if results[0] == textLenNotFound {
+		return 0, fmt.Errorf("failed to get text length: buffer not found")
+	}
+
+	return int(results[0]), nil
+}
+
+// RuneLen returns the length of the buffer text in runes, the unit used by cursor positions
+func (b *Buffer) RuneLen() (int, error) {
+	text, err := b.Text()
+	if err != nil {
+		return 0, err
+	}
+	return utf8.RuneCountInString(text), nil
+}
+
+// IsEmpty reports whether the buffer text is empty, without copying the text
+// out of the WASM module
+func (b *Buffer) IsEmpty() (bool, error) {
+	n, err := b.ByteLen()
+	if err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
+
 // CursorPosition returns the current cursor position in rune index
 func (b *Buffer) CursorPosition() (int, error) {
 	if b == nil || b.parser == nil || b.parser.module == nil {
@@ -96,6 +176,22 @@ func (b *Buffer) DisplayCursorPosition() (int, error) {
 	return doc.DisplayCursorPosition()
 }
 
+// DisplayColumnAtCursor returns the cursor column within the current line,
+// counting wide characters (like CJK) as two columns
+func (b *Buffer) DisplayColumnAtCursor() (int, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return 0, fmt.Errorf("buffer is nil or closed")
+	}
+
+	doc, err := b.Document()
+	if err != nil {
+		return 0, err
+	}
+	defer doc.Close()
+
+	return doc.DisplayColumn()
+}
+
 // InsertText inserts text at the current cursor position
 func (b *Buffer) InsertText(text string, overwrite bool, moveCursor bool) error {
 	if b == nil || b.parser == nil || b.parser.module == nil {
@@ -107,6 +203,13 @@ func (b *Buffer) InsertText(text string, overwrite bool, moveCursor bool) error
 		return fmt.Errorf("WASM module does not export 'buffer_insert_text' function")
 	}
 
+	// Single characters typed at the cursor may join the previous undo step
+	typing := b.undoEnabled && b.undoCoalescing && moveCursor && !overwrite && text != "\n" && utf8.RuneCountInString(text) == 1
+	undoState, err := b.snapshotForInsert(typing)
+	if err != nil {
+		return err
+	}
+
 	textPtr, err := b.parser.allocateString(text)
 	if err != nil {
 		return err
@@ -127,10 +230,32 @@ func (b *Buffer) InsertText(text string, overwrite bool, moveCursor bool) error
 		return fmt.Errorf("failed to insert text: %w", err)
 	}
 
+	if text != "" {
+		b.pushUndoState(undoState)
+	}
+	if typing {
+		b.markTyping()
+	}
+
 	return nil
 }
 
-// DeleteBeforeCursor deletes count characters before the cursor and returns the deleted text
+// InsertMultiline inserts a multi-line block at the cursor in a single WASM call
+// (plus a state snapshot when undo is enabled) and leaves the cursor at the end
+// of the block. "\r\n" and "\r" line endings
+// (as sent by terminals on paste) are normalized to "\n".
+func (b *Buffer) InsertMultiline(text string) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return b.InsertText(text, false, true)
+}
+
+// DeleteBeforeCursor deletes count characters before the cursor and returns the deleted text.
+// Newlines count as characters, so at the start of a line it joins the line to the previous one.
 func (b *Buffer) DeleteBeforeCursor(count int) (string, error) {
 	if b == nil || b.parser == nil || b.parser.module == nil {
 		return "", fmt.Errorf("buffer is nil or closed")
@@ -141,6 +266,11 @@ func (b *Buffer) DeleteBeforeCursor(count int) (string, error) {
 		return "", fmt.Errorf("WASM module does not export 'buffer_delete_before_cursor' function")
 	}
 
+	undoState, err := b.snapshot()
+	if err != nil {
+		return "", err
+	}
+
 	results, err := deleteBeforeFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
 	if err != nil {
 		return "", fmt.Errorf("failed to delete before cursor: %w", err)
@@ -151,9 +281,192 @@ func (b *Buffer) DeleteBeforeCursor(count int) (string, error) {
 		return "", err
 	}
 
+	if deletedText != "" {
+		b.pushUndoState(undoState)
+	}
+
 	return deletedText, nil
 }
 
+// DeleteWordBeforeCursor deletes the word before the cursor (Ctrl+W) and returns the
+// deleted text. Whitespace directly before the cursor is deleted with the word, and
+// punctuation is treated as a separate word. In subword mode only the subword
+// before the cursor is deleted.
+func (b *Buffer) DeleteWordBeforeCursor() (string, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return "", fmt.Errorf("buffer is nil or closed")
+	}
+
+	if b.subwordMode {
+		chars, pos, err := b.textAndCursor()
+		if err != nil {
+			return "", err
+		}
+		return b.kill(b.DeleteBeforeCursor(pos - previousWordStart(chars, pos, true)))
+	}
+
+	deleteWordFn := b.parser.module.ExportedFunction("buffer_delete_word_before_cursor")
+	if deleteWordFn == nil {
+		return "", fmt.Errorf("WASM module does not export 'buffer_delete_word_before_cursor' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return "", err
+	}
+
+	results, err := deleteWordFn.Call(b.parser.ctx, uint64(b.bufferID))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete word before cursor: %w", err)
+	}
+
+	var deletedText string
+	if err := b.parser.readJSONResult(results[0], &deletedText); err != nil {
+		return "", err
+	}
+
+	if deletedText != "" {
+		b.pushUndoState(undoState)
+	}
+
+	return b.kill(deletedText, nil)
+}
+
+// DeleteWordAfterCursor deletes the word after the cursor (Alt+D) and returns the
+// deleted text. Whitespace directly after the cursor is deleted with the word.
+// In subword mode only the subword after the cursor is deleted.
+func (b *Buffer) DeleteWordAfterCursor() (string, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return "", fmt.Errorf("buffer is nil or closed")
+	}
+
+	if b.subwordMode {
+		chars, pos, err := b.textAndCursor()
+		if err != nil {
+			return "", err
+		}
+		return b.kill(b.Delete(nextWordEnd(chars, pos, true) - pos))
+	}
+
+	deleteWordFn := b.parser.module.ExportedFunction("buffer_delete_word_after_cursor")
+	if deleteWordFn == nil {
+		return "", fmt.Errorf("WASM module does not export 'buffer_delete_word_after_cursor' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return "", err
+	}
+
+	results, err := deleteWordFn.Call(b.parser.ctx, uint64(b.bufferID))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete word after cursor: %w", err)
+	}
+
+	var deletedText string
+	if err := b.parser.readJSONResult(results[0], &deletedText); err != nil {
+		return "", err
+	}
+
+	if deletedText != "" {
+		b.pushUndoState(undoState)
+	}
+
+	return b.kill(deletedText, nil)
+}
+
+// DeleteToLineEnd deletes from the cursor to the end of the current line (Ctrl+K)
+// and returns the deleted text for a kill ring. The newline ending the line is
+// kept, so at the end of a line nothing is deleted.
+func (b *Buffer) DeleteToLineEnd() (string, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return "", fmt.Errorf("buffer is nil or closed")
+	}
+
+	doc, err := b.Document()
+	if err != nil {
+		return "", err
+	}
+	defer doc.Close()
+
+	_, after, err := doc.CurrentLineSplit()
+	if err != nil {
+		return "", err
+	}
+	return b.kill(b.Delete(utf8.RuneCountInString(after)))
+}
+
+// DeleteToLineStart deletes from the start of the current line to the cursor
+// (Ctrl+U) and returns the deleted text for a kill ring. The newline before
+// the line is kept, so at the start of a line nothing is deleted.
+func (b *Buffer) DeleteToLineStart() (string, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return "", fmt.Errorf("buffer is nil or closed")
+	}
+
+	doc, err := b.Document()
+	if err != nil {
+		return "", err
+	}
+	defer doc.Close()
+
+	before, _, err := doc.CurrentLineSplit()
+	if err != nil {
+		return "", err
+	}
+	return b.kill(b.DeleteBeforeCursor(utf8.RuneCountInString(before)))
+}
+
+// SetSubwordMode controls whether word motions and word deletions stop at
+// subword boundaries inside identifiers: at case changes in camelCase
+// ("get|Foo|Bar", "HTTP|Server") and around underscores in snake_case, which
+// are skipped like whitespace ("get|_foo|_bar"). It is disabled by default.
+func (b *Buffer) SetSubwordMode(enabled bool) {
+	b.subwordMode = enabled
+}
+
+// CursorWordLeft moves the cursor to the start of the word before it, over
+// any whitespace in between. Words are those removed by DeleteWordBeforeCursor.
+func (b *Buffer) CursorWordLeft() error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	chars, pos, err := b.textAndCursor()
+	if err != nil {
+		return err
+	}
+	return b.SetCursorPosition(previousWordStart(chars, pos, b.subwordMode))
+}
+
+// CursorWordRight moves the cursor to the end of the word after it, over any
+// whitespace in between. Words are those removed by DeleteWordAfterCursor.
+func (b *Buffer) CursorWordRight() error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	chars, pos, err := b.textAndCursor()
+	if err != nil {
+		return err
+	}
+	return b.SetCursorPosition(nextWordEnd(chars, pos, b.subwordMode))
+}
+
+// textAndCursor returns the buffer text as runes and the cursor position
+func (b *Buffer) textAndCursor() ([]rune, int, error) {
+	state, err := b.ToWasmState()
+	if err != nil {
+		return nil, 0, err
+	}
+	if state.WorkingIndex < 0 || state.WorkingIndex >= len(state.WorkingLines) {
+		return nil, 0, fmt.Errorf("invalid buffer state: working index %d out of range", state.WorkingIndex)
+	}
+
+	chars := []rune(state.WorkingLines[state.WorkingIndex])
+	return chars, min(state.CursorPosition, len(chars)), nil
+}
+
 // Delete deletes count characters after the cursor and returns the deleted text
 func (b *Buffer) Delete(count int) (string, error) {
 	if b == nil || b.parser == nil || b.parser.module == nil {
@@ -165,6 +478,11 @@ func (b *Buffer) Delete(count int) (string, error) {
 		return "", fmt.Errorf("WASM module does not export 'buffer_delete' function")
 	}
 
+	undoState, err := b.snapshot()
+	if err != nil {
+		return "", err
+	}
+
 	results, err := deleteFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
 	if err != nil {
 		return "", fmt.Errorf("failed to delete: %w", err)
@@ -175,6 +493,10 @@ func (b *Buffer) Delete(count int) (string, error) {
 		return "", err
 	}
 
+	if deletedText != "" {
+		b.pushUndoState(undoState)
+	}
+
 	return deletedText, nil
 }
 
@@ -208,162 +530,670 @@ func (b *Buffer) CursorRight(count int) error {
 		return fmt.Errorf("WASM module does not export 'buffer_cursor_right' function")
 	}
 
-	_, err := cursorRightFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
-	if err != nil {
-		return fmt.Errorf("failed to move cursor right: %w", err)
+	_, err := cursorRightFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
+	if err != nil {
+		return fmt.Errorf("failed to move cursor right: %w", err)
+	}
+
+	return nil
+}
+
+// CursorUp moves the cursor up by count lines
+func (b *Buffer) CursorUp(count int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	cursorUpFn := b.parser.module.ExportedFunction("buffer_cursor_up")
+	if cursorUpFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_cursor_up' function")
+	}
+
+	_, err := cursorUpFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
+	if err != nil {
+		return fmt.Errorf("failed to move cursor up: %w", err)
+	}
+
+	return nil
+}
+
+// CursorDown moves the cursor down by count lines
+func (b *Buffer) CursorDown(count int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	cursorDownFn := b.parser.module.ExportedFunction("buffer_cursor_down")
+	if cursorDownFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_cursor_down' function")
+	}
+
+	_, err := cursorDownFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
+	if err != nil {
+		return fmt.Errorf("failed to move cursor down: %w", err)
+	}
+
+	return nil
+}
+
+// SetText sets the buffer text and resets cursor position
+func (b *Buffer) SetText(text string) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	setTextFn := b.parser.module.ExportedFunction("buffer_set_text")
+	if setTextFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_set_text' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	textPtr, err := b.parser.allocateString(text)
+	if err != nil {
+		return err
+	}
+	defer b.parser.freeMemory(textPtr)
+
+	_, err = setTextFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(textPtr), uint64(len(text)))
+	if err != nil {
+		return fmt.Errorf("failed to set text: %w", err)
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// SetCursorPosition sets the cursor position to the specified rune index
+func (b *Buffer) SetCursorPosition(position int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	setCursorPosFn := b.parser.module.ExportedFunction("buffer_set_cursor_position")
+	if setCursorPosFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_set_cursor_position' function")
+	}
+
+	_, err := setCursorPosFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(position))
+	if err != nil {
+		return fmt.Errorf("failed to set cursor position: %w", err)
+	}
+
+	return nil
+}
+
+// NewLine creates a new line at the cursor position
+func (b *Buffer) NewLine(copyMargin bool) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	newLineFn := b.parser.module.ExportedFunction("buffer_new_line")
+	if newLineFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_new_line' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	copyMarginFlag := uint64(0)
+	if copyMargin {
+		copyMarginFlag = 1
+	}
+
+	_, err = newLineFn.Call(b.parser.ctx, uint64(b.bufferID), copyMarginFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create new line: %w", err)
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// JoinNextLine joins the current line with the next line using the specified separator
+func (b *Buffer) JoinNextLine(separator string) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	joinNextLineFn := b.parser.module.ExportedFunction("buffer_join_next_line")
+	if joinNextLineFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_join_next_line' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	sepPtr, err := b.parser.allocateString(separator)
+	if err != nil {
+		return err
+	}
+	defer b.parser.freeMemory(sepPtr)
+
+	_, err = joinNextLineFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(sepPtr), uint64(len(separator)))
+	if err != nil {
+		return fmt.Errorf("failed to join next line: %w", err)
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// SwapCharactersBeforeCursor swaps the two characters before the cursor
+func (b *Buffer) SwapCharactersBeforeCursor() error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	swapCharsFn := b.parser.module.ExportedFunction("buffer_swap_characters_before_cursor")
+	if swapCharsFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_swap_characters_before_cursor' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	_, err = swapCharsFn.Call(b.parser.ctx, uint64(b.bufferID))
+	if err != nil {
+		return fmt.Errorf("failed to swap characters: %w", err)
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// UppercaseRange converts the text between the start and end rune indices to uppercase.
+// Full Unicode case mapping is used, so the text may grow (e.g. "ß" becomes "SS");
+// the cursor keeps its logical position. Indices past the end of the text are
+// clamped; negative indices are an error.
+func (b *Buffer) UppercaseRange(start, end int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+	if start < 0 || end < 0 {
+		return fmt.Errorf("invalid range: %d to %d", start, end)
+	}
+
+	uppercaseRangeFn := b.parser.module.ExportedFunction("buffer_uppercase_range")
+	if uppercaseRangeFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_uppercase_range' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	results, err := uppercaseRangeFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(start), uint64(end))
+	if err != nil {
+		return fmt.Errorf("failed to uppercase range: %w", err)
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to uppercase range")
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// LowercaseRange converts the text between the start and end rune indices to lowercase.
+// Full Unicode case mapping is used; the cursor keeps its logical position.
+// Indices are handled as in UppercaseRange.
+func (b *Buffer) LowercaseRange(start, end int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+	if start < 0 || end < 0 {
+		return fmt.Errorf("invalid range: %d to %d", start, end)
+	}
+
+	lowercaseRangeFn := b.parser.module.ExportedFunction("buffer_lowercase_range")
+	if lowercaseRangeFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_lowercase_range' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	results, err := lowercaseRangeFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(start), uint64(end))
+	if err != nil {
+		return fmt.Errorf("failed to lowercase range: %w", err)
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to lowercase range")
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// UppercaseWord uppercases from the cursor to the end of the next word (Alt+U)
+// and moves the cursor past it. Non-word characters before the word are
+// skipped. Case mapping is full Unicode and locale-independent: "ß" becomes
+// "SS" and the Turkish dotless "ı" becomes "I".
+func (b *Buffer) UppercaseWord() error {
+	return b.transformCase("buffer_uppercase_word", "uppercase word")
+}
+
+// LowercaseWord lowercases from the cursor to the end of the next word (Alt+L)
+// and moves the cursor past it. The Turkish dotted "İ" becomes "i" followed by
+// U+0307 COMBINING DOT ABOVE, so the text may grow.
+func (b *Buffer) LowercaseWord() error {
+	return b.transformCase("buffer_lowercase_word", "lowercase word")
+}
+
+// CapitalizeWord uppercases the first character of the next word and
+// lowercases the rest of it (Alt+C), then moves the cursor past it. A leading
+// "ß" becomes "Ss".
+func (b *Buffer) CapitalizeWord() error {
+	return b.transformCase("buffer_capitalize_word", "capitalize word")
+}
+
+// transformCase calls one of the word or whole-text case exports and records
+// the change for undo
+func (b *Buffer) transformCase(export, action string) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+
+	transformFn := b.parser.module.ExportedFunction(export)
+	if transformFn == nil {
+		return fmt.Errorf("WASM module does not export '%s' function", export)
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	results, err := transformFn.Call(b.parser.ctx, uint64(b.bufferID))
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to %s", action)
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// WordWrap reflows the text to wrap at word boundaries within width display columns.
+// Existing line breaks are kept as paragraph breaks; the cursor stays near its logical position.
+func (b *Buffer) WordWrap(width int) error {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return fmt.Errorf("buffer is nil or closed")
+	}
+	if width < 0 {
+		return fmt.Errorf("invalid wrap width: %d", width)
+	}
+
+	wordWrapFn := b.parser.module.ExportedFunction("buffer_word_wrap")
+	if wordWrapFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_word_wrap' function")
+	}
+
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
+	}
+
+	results, err := wordWrapFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(width))
+	if err != nil {
+		return fmt.Errorf("failed to wrap text: %w", err)
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to wrap text")
+	}
+
+	b.pushUndoState(undoState)
+
+	return nil
+}
+
+// UppercaseText converts the whole buffer text to uppercase
+func (b *Buffer) UppercaseText() error {
+	return b.transformCase("buffer_uppercase_text", "uppercase text")
+}
+
+// LowercaseText converts the whole buffer text to lowercase
+func (b *Buffer) LowercaseText() error {
+	return b.transformCase("buffer_lowercase_text", "lowercase text")
+}
+
+// SetSelectionAnchor starts a selection at the current cursor position. The
+// selection spans from the anchor to wherever the cursor moves afterwards.
+func (b *Buffer) SetSelectionAnchor() error {
+	pos, err := b.CursorPosition()
+	if err != nil {
+		return err
+	}
+
+	b.selectionAnchor = pos
+	b.hasAnchor = true
+	return nil
+}
+
+// ClearSelection drops the selection anchor
+func (b *Buffer) ClearSelection() {
+	b.hasAnchor = false
+}
+
+// HasSelection reports whether a selection anchor is set
+func (b *Buffer) HasSelection() bool {
+	return b.hasAnchor
+}
+
+// SelectionRange returns the selected rune range [start, end) between the anchor
+// and the cursor, regardless of which comes first. Without an anchor, both
+// bounds are the cursor position.
+func (b *Buffer) SelectionRange() (start int, end int, err error) {
+	text, err := b.Text()
+	if err != nil {
+		return 0, 0, err
+	}
+	pos, err := b.CursorPosition()
+	if err != nil {
+		return 0, 0, err
+	}
+	if !b.hasAnchor {
+		return pos, pos, nil
+	}
+
+	// Edits since the anchor was set may have shortened the text
+	anchor := min(b.selectionAnchor, utf8.RuneCountInString(text))
+	return min(anchor, pos), max(anchor, pos), nil
+}
+
+// GetSelectedText returns the text between the selection anchor and the cursor,
+// or an empty string when there is no selection
+func (b *Buffer) GetSelectedText() (string, error) {
+	start, end, err := b.SelectionRange()
+	if err != nil || start == end {
+		return "", err
+	}
+
+	text, err := b.Text()
+	if err != nil {
+		return "", err
+	}
+	return string([]rune(text)[start:end]), nil
+}
+
+// DeleteSelection removes the selected text, leaves the cursor at the start of
+// the removed span, clears the selection and returns the deleted text
+func (b *Buffer) DeleteSelection() (string, error) {
+	start, end, err := b.SelectionRange()
+	if err != nil {
+		return "", err
+	}
+	b.ClearSelection()
+	if start == end {
+		return "", nil
+	}
+
+	if err := b.SetCursorPosition(end); err != nil {
+		return "", err
 	}
+	return b.DeleteBeforeCursor(end - start)
+}
 
-	return nil
+// UppercaseSelection converts the selected text to uppercase with full Unicode
+// case mapping, so "ß" becomes "SS". The selection is kept and still covers the
+// converted text when it grows. It does nothing when the selection is empty.
+func (b *Buffer) UppercaseSelection() error {
+	return b.transformSelection(b.UppercaseRange)
 }
 
-// CursorUp moves the cursor up by count lines
-func (b *Buffer) CursorUp(count int) error {
-	if b == nil || b.parser == nil || b.parser.module == nil {
-		return fmt.Errorf("buffer is nil or closed")
-	}
+// LowercaseSelection converts the selected text to lowercase. It otherwise
+// behaves like UppercaseSelection.
+func (b *Buffer) LowercaseSelection() error {
+	return b.transformSelection(b.LowercaseRange)
+}
 
-	cursorUpFn := b.parser.module.ExportedFunction("buffer_cursor_up")
-	if cursorUpFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_cursor_up' function")
+// transformSelection applies a range case conversion to the selection and
+// moves an anchor at the end of it by the change in length
+func (b *Buffer) transformSelection(transform func(start, end int) error) error {
+	start, end, err := b.SelectionRange()
+	if err != nil || start == end {
+		return err
 	}
 
-	_, err := cursorUpFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
+	before, err := b.RuneLen()
 	if err != nil {
-		return fmt.Errorf("failed to move cursor up: %w", err)
+		return err
+	}
+	if err := transform(start, end); err != nil {
+		return err
+	}
+	after, err := b.RuneLen()
+	if err != nil {
+		return err
 	}
 
+	if b.selectionAnchor >= end {
+		b.selectionAnchor = end + after - before
+	}
 	return nil
 }
 
-// CursorDown moves the cursor down by count lines
-func (b *Buffer) CursorDown(count int) error {
+// BufferEditOp identifies the operation performed by a BufferEdit
+type BufferEditOp string
+
+// Operations supported by ApplyEdits
+const (
+	EditInsert             BufferEditOp = "insert"
+	EditDeleteBeforeCursor BufferEditOp = "delete_before_cursor"
+	EditDelete             BufferEditOp = "delete"
+	EditCursorLeft         BufferEditOp = "cursor_left"
+	EditCursorRight        BufferEditOp = "cursor_right"
+	EditCursorUp           BufferEditOp = "cursor_up"
+	EditCursorDown         BufferEditOp = "cursor_down"
+	EditSetCursorPosition  BufferEditOp = "set_cursor_position"
+)
+
+// BufferEdit describes one operation in an ApplyEdits batch. Text and Overwrite
+// apply to EditInsert, Count to deletes and relative cursor moves, and Position
+// to EditSetCursorPosition.
+type BufferEdit struct {
+	Op        BufferEditOp `json:"op"`
+	Text      string       `json:"text,omitempty"`
+	Overwrite bool         `json:"overwrite,omitempty"`
+	Count     int          `json:"count,omitempty"`
+	Position  int          `json:"position,omitempty"`
+}
+
+// ApplyEdits applies edits in order using a single WASM call, which is much
+// cheaper than one call per operation when replaying many keystrokes. Inserts
+// always move the cursor. With undo enabled the buffer state is also saved
+// first, and the batch is recorded as one undo step. If the batch cannot be
+// decoded by the WASM module, no edit is applied.
+func (b *Buffer) ApplyEdits(edits []BufferEdit) error {
 	if b == nil || b.parser == nil || b.parser.module == nil {
 		return fmt.Errorf("buffer is nil or closed")
 	}
-
-	cursorDownFn := b.parser.module.ExportedFunction("buffer_cursor_down")
-	if cursorDownFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_cursor_down' function")
+	if len(edits) == 0 {
+		return nil
 	}
 
-	_, err := cursorDownFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(count))
-	if err != nil {
-		return fmt.Errorf("failed to move cursor down: %w", err)
+	applyEditsFn := b.parser.module.ExportedFunction("buffer_apply_edits")
+	if applyEditsFn == nil {
+		return fmt.Errorf("WASM module does not export 'buffer_apply_edits' function")
 	}
 
-	return nil
-}
-
-// SetText sets the buffer text and resets cursor position
-func (b *Buffer) SetText(text string) error {
-	if b == nil || b.parser == nil || b.parser.module == nil {
-		return fmt.Errorf("buffer is nil or closed")
+	undoState, err := b.snapshot()
+	if err != nil {
+		return err
 	}
 
-	setTextFn := b.parser.module.ExportedFunction("buffer_set_text")
-	if setTextFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_set_text' function")
+	editsJSON, err := json.Marshal(edits)
+	if err != nil {
+		return fmt.Errorf("failed to marshal buffer edits: %w", err)
 	}
 
-	textPtr, err := b.parser.allocateString(text)
+	editsPtr, err := b.parser.allocateString(string(editsJSON))
 	if err != nil {
 		return err
 	}
-	defer b.parser.freeMemory(textPtr)
+	defer b.parser.freeMemory(editsPtr)
 
-	_, err = setTextFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(textPtr), uint64(len(text)))
+	results, err := applyEditsFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(editsPtr), uint64(len(editsJSON)))
 	if err != nil {
-		return fmt.Errorf("failed to set text: %w", err)
+		return fmt.Errorf("failed to apply edits: %w", err)
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to apply edits: invalid edit batch")
 	}
 
+	b.pushUndoState(undoState)
+
 	return nil
 }
 
-// SetCursorPosition sets the cursor position to the specified rune index
-func (b *Buffer) SetCursorPosition(position int) error {
-	if b == nil || b.parser == nil || b.parser.module == nil {
-		return fmt.Errorf("buffer is nil or closed")
-	}
-
-	setCursorPosFn := b.parser.module.ExportedFunction("buffer_set_cursor_position")
-	if setCursorPosFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_set_cursor_position' function")
-	}
-
-	_, err := setCursorPosFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(position))
-	if err != nil {
-		return fmt.Errorf("failed to set cursor position: %w", err)
+// SetUndoEnabled turns undo history on or off. It is off by default: every
+// recorded step costs a full copy of the buffer state, taken before each edit,
+// which callers that never undo should not pay for. Turning it off drops the
+// existing history.
+func (b *Buffer) SetUndoEnabled(enabled bool) {
+	b.undoEnabled = enabled
+	if !enabled {
+		b.undoStack = nil
+		b.redoStack = nil
+		b.typingRun = false
 	}
+}
 
-	return nil
+// SetUndoCoalescing controls whether consecutive single-character inserts at the
+// cursor are merged into one undo step, so Undo removes a whole typed run instead
+// of one character at a time. It is disabled by default and only has an effect
+// while undo is enabled.
+func (b *Buffer) SetUndoCoalescing(enabled bool) {
+	b.undoCoalescing = enabled
+	b.typingRun = false
 }
 
-// NewLine creates a new line at the cursor position
-func (b *Buffer) NewLine(copyMargin bool) error {
+// Undo restores the buffer text and cursor to the state before the last editing
+// operation. It does nothing when there is nothing to undo, which is always the
+// case unless SetUndoEnabled was called.
+func (b *Buffer) Undo() error {
 	if b == nil || b.parser == nil || b.parser.module == nil {
 		return fmt.Errorf("buffer is nil or closed")
 	}
-
-	newLineFn := b.parser.module.ExportedFunction("buffer_new_line")
-	if newLineFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_new_line' function")
-	}
-
-	copyMarginFlag := uint64(0)
-	if copyMargin {
-		copyMarginFlag = 1
+	if len(b.undoStack) == 0 {
+		return nil
 	}
 
-	_, err := newLineFn.Call(b.parser.ctx, uint64(b.bufferID), copyMarginFlag)
+	current, err := b.snapshot()
 	if err != nil {
-		return fmt.Errorf("failed to create new line: %w", err)
+		return err
+	}
+	if err := b.restoreState(b.undoStack[len(b.undoStack)-1]); err != nil {
+		return err
 	}
 
+	b.undoStack = b.undoStack[:len(b.undoStack)-1]
+	b.redoStack = append(b.redoStack, current)
+	b.typingRun = false
 	return nil
 }
 
-// JoinNextLine joins the current line with the next line using the specified separator
-func (b *Buffer) JoinNextLine(separator string) error {
+// Redo reapplies the last operation reverted by Undo. It does nothing when there
+// is nothing to redo; any new edit clears the redo history.
+func (b *Buffer) Redo() error {
 	if b == nil || b.parser == nil || b.parser.module == nil {
 		return fmt.Errorf("buffer is nil or closed")
 	}
-
-	joinNextLineFn := b.parser.module.ExportedFunction("buffer_join_next_line")
-	if joinNextLineFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_join_next_line' function")
+	if len(b.redoStack) == 0 {
+		return nil
 	}
 
-	sepPtr, err := b.parser.allocateString(separator)
+	current, err := b.snapshot()
 	if err != nil {
 		return err
 	}
-	defer b.parser.freeMemory(sepPtr)
-
-	_, err = joinNextLineFn.Call(b.parser.ctx, uint64(b.bufferID), uint64(sepPtr), uint64(len(separator)))
-	if err != nil {
-		return fmt.Errorf("failed to join next line: %w", err)
+	if err := b.restoreState(b.redoStack[len(b.redoStack)-1]); err != nil {
+		return err
 	}
 
+	b.redoStack = b.redoStack[:len(b.redoStack)-1]
+	b.undoStack = append(b.undoStack, current)
+	b.typingRun = false
 	return nil
 }
 
-// SwapCharactersBeforeCursor swaps the two characters before the cursor
-func (b *Buffer) SwapCharactersBeforeCursor() error {
-	if b == nil || b.parser == nil || b.parser.module == nil {
-		return fmt.Errorf("buffer is nil or closed")
+// snapshot captures the buffer state before a mutating operation. It returns
+// nil without calling into WASM when undo is disabled.
+func (b *Buffer) snapshot() (*WasmBufferState, error) {
+	if !b.undoEnabled {
+		return nil, nil
+	}
+	state, err := b.ToWasmState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save undo state: %w", err)
 	}
+	return state, nil
+}
 
-	swapCharsFn := b.parser.module.ExportedFunction("buffer_swap_characters_before_cursor")
-	if swapCharsFn == nil {
-		return fmt.Errorf("WASM module does not export 'buffer_swap_characters_before_cursor' function")
+// snapshotForInsert is snapshot for InsertText. It returns nil when typing
+// continues the current run of single-character inserts.
+func (b *Buffer) snapshotForInsert(typing bool) (*WasmBufferState, error) {
+	if typing && b.typingRun {
+		if pos, err := b.CursorPosition(); err == nil && pos == b.typingEnd {
+			return nil, nil
+		}
+	}
+	return b.snapshot()
+}
+
+// pushUndoState records state as an undo step and clears the redo history
+func (b *Buffer) pushUndoState(state *WasmBufferState) {
+	if state == nil {
+		return
 	}
+	b.undoStack = append(b.undoStack, state)
+	if len(b.undoStack) > maxUndoStates {
+		b.undoStack = b.undoStack[1:]
+	}
+	b.redoStack = nil
+	b.typingRun = false
+}
 
-	_, err := swapCharsFn.Call(b.parser.ctx, uint64(b.bufferID))
+// markTyping records where a coalescible insert left the cursor
+func (b *Buffer) markTyping() {
+	pos, err := b.CursorPosition()
+	b.typingRun = err == nil
+	b.typingEnd = pos
+}
+
+// restoreState replaces the underlying WASM buffer with one built from state
+func (b *Buffer) restoreState(state *WasmBufferState) error {
+	restored, err := b.parser.BufferFromWasmState(state)
 	if err != nil {
-		return fmt.Errorf("failed to swap characters: %w", err)
+		return err
 	}
 
-	return nil
+	previous := &Buffer{parser: b.parser, bufferID: b.bufferID}
+	b.bufferID = restored.bufferID
+	return previous.Close()
 }
 
 // Document returns the current Document for text analysis operations
@@ -447,6 +1277,44 @@ func (p *KeyParser) BufferFromWasmState(state *WasmBufferState) (*Buffer, error)
 	}, nil
 }
 
+// Copy returns an independent buffer with the same text, cursor, working lines,
+// undo settings and history, and selection. Edits to either buffer do not
+// affect the other; the copy must be closed separately.
+//
+// The kill ring is the exception: the copy shares the original's ring (see
+// SetKillRing), so text killed in either buffer can be yanked in both. Call
+// SetKillRing on the copy to give it a ring of its own. A pending Yank is not
+// carried over, so YankPop on the copy returns an error until it yanks.
+func (b *Buffer) Copy() (*Buffer, error) {
+	if b == nil || b.parser == nil || b.parser.module == nil {
+		return nil, fmt.Errorf("buffer is nil or closed")
+	}
+
+	state, err := b.ToWasmState()
+	if err != nil {
+		return nil, err
+	}
+
+	clone, err := b.parser.BufferFromWasmState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saved states are never modified, so only the stacks need copying
+	clone.undoStack = append([]*WasmBufferState(nil), b.undoStack...)
+	clone.redoStack = append([]*WasmBufferState(nil), b.redoStack...)
+	clone.undoEnabled = b.undoEnabled
+	clone.undoCoalescing = b.undoCoalescing
+	clone.typingRun = b.typingRun
+	clone.typingEnd = b.typingEnd
+	clone.selectionAnchor = b.selectionAnchor
+	clone.hasAnchor = b.hasAnchor
+	clone.subwordMode = b.subwordMode
+	clone.killRing = b.killRing // Shared, as documented above
+
+	return clone, nil
+}
+
 // Close releases the buffer resources
 func (b *Buffer) Close() error {
 	if b == nil || b.parser == nil || b.parser.module == nil {
@@ -526,6 +1394,60 @@ func (p *KeyParser) NewDocumentWithText(text string, cursorPosition int) (*Docum
 	}, nil
 }
 
+// DocumentSpec describes one document in a NewDocumentsWithText batch
+type DocumentSpec struct {
+	Text   string `json:"text"`
+	Cursor int    `json:"cursor"`
+}
+
+// NewDocumentsWithText creates one Document per spec using a single WASM call,
+// which is much cheaper than calling NewDocumentWithText in a loop when a
+// completer scores many candidates. Documents are returned in spec order and
+// can be released together with CloseDocuments.
+func (p *KeyParser) NewDocumentsWithText(specs []DocumentSpec) ([]*Document, error) {
+	if p == nil || p.module == nil {
+		return nil, fmt.Errorf("parser is nil or closed")
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	docsWithTextFn := p.module.ExportedFunction("documents_with_text")
+	if docsWithTextFn == nil {
+		return nil, fmt.Errorf("WASM module does not export 'documents_with_text' function")
+	}
+
+	specsJSON, err := json.Marshal(specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document specs: %w", err)
+	}
+
+	specsPtr, err := p.allocateString(string(specsJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer p.freeMemory(specsPtr)
+
+	results, err := docsWithTextFn.Call(p.ctx, uint64(specsPtr), uint64(len(specsJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create documents: %w", err)
+	}
+
+	var ids []uint32
+	if err := p.readJSONResult(results[0], &ids); err != nil {
+		return nil, fmt.Errorf("failed to create documents: %w", err)
+	}
+
+	documents := make([]*Document, len(ids))
+	for i, id := range ids {
+		documents[i] = &Document{
+			parser:     p,
+			documentID: id,
+		}
+	}
+	return documents, nil
+}
+
 // NewDocumentWithTextAndKey creates a new Document with text, cursor position, and last key
 func (p *KeyParser) NewDocumentWithTextAndKey(text string, cursorPosition int, lastKey *Key) (*Document, error) {
 	if p == nil || p.module == nil {
@@ -577,6 +1499,48 @@ func (d *Document) Text() (string, error) {
 	return state.Text, nil
 }
 
+// ByteLen returns the length of the document text in UTF-8 bytes, as len(text) would,
+// without copying the text out of the WASM module
+func (d *Document) ByteLen() (int, error) {
+	if d == nil || d.parser == nil || d.parser.module == nil {
+		return 0, fmt.Errorf("document is nil or closed")
+	}
+
+	textLenFn := d.parser.module.ExportedFunction("document_text_len")
+	if textLenFn == nil {
+		return 0, fmt.Errorf("WASM module does not export 'document_text_len' function")
+	}
+
+	results, err := textLenFn.Call(d.parser.ctx, uint64(d.documentID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get text length: %w", err)
+	}
+	if results[0] == textLenNotFound {
+		return 0, fmt.Errorf("failed to get text length: document not found")
+	}
+
+	return int(results[0]), nil
+}
+
+// RuneLen returns the length of the document text in runes, the unit used by cursor positions
+func (d *Document) RuneLen() (int, error) {
+	text, err := d.Text()
+	if err != nil {
+		return 0, err
+	}
+	return utf8.RuneCountInString(text), nil
+}
+
+// IsEmpty reports whether the document text is empty, without copying the
+// text out of the WASM module
+func (d *Document) IsEmpty() (bool, error) {
+	n, err := d.ByteLen()
+	if err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
+
 // CursorPosition returns the cursor position in rune index
 func (d *Document) CursorPosition() (int, error) {
 	if d == nil || d.parser == nil || d.parser.module == nil {
@@ -706,6 +1670,64 @@ func (d *Document) GetWordAfterCursor() (string, error) {
 	return word, nil
 }
 
+// CurrentWord returns the whole word containing the cursor and its rune bounds.
+// A cursor directly before or after a word counts as inside it; on whitespace
+// the word is empty and start == end == the cursor position.
+func (d *Document) CurrentWord() (word string, start int, end int, err error) {
+	if d == nil || d.parser == nil || d.parser.module == nil {
+		return "", 0, 0, fmt.Errorf("document is nil or closed")
+	}
+
+	currentWordFn := d.parser.module.ExportedFunction("document_current_word")
+	if currentWordFn == nil {
+		return "", 0, 0, fmt.Errorf("WASM module does not export 'document_current_word' function")
+	}
+
+	results, err := currentWordFn.Call(d.parser.ctx, uint64(d.documentID))
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to get current word: %w", err)
+	}
+
+	var result struct {
+		Word  string `json:"word"`
+		Start int    `json:"start"`
+		End   int    `json:"end"`
+	}
+	if err := d.parser.readJSONResult(results[0], &result); err != nil {
+		return "", 0, 0, err
+	}
+
+	return result.Word, result.Start, result.End, nil
+}
+
+// CurrentLineSplit returns the current line's text before and after the cursor,
+// excluding the newlines that delimit the line
+func (d *Document) CurrentLineSplit() (before string, after string, err error) {
+	if d == nil || d.parser == nil || d.parser.module == nil {
+		return "", "", fmt.Errorf("document is nil or closed")
+	}
+
+	currentLineSplitFn := d.parser.module.ExportedFunction("document_current_line_split")
+	if currentLineSplitFn == nil {
+		return "", "", fmt.Errorf("WASM module does not export 'document_current_line_split' function")
+	}
+
+	results, err := currentLineSplitFn.Call(d.parser.ctx, uint64(d.documentID))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current line split: %w", err)
+	}
+
+	var result struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	}
+	if err := d.parser.readJSONResult(results[0], &result); err != nil {
+		return "", "", err
+	}
+
+	return result.Before, result.After, nil
+}
+
 // CurrentLine returns the current line text
 func (d *Document) CurrentLine() (string, error) {
 	if d == nil || d.parser == nil || d.parser.module == nil {
@@ -787,6 +1809,76 @@ func (d *Document) CursorPositionCol() (int, error) {
 	return int(results[0]), nil
 }
 
+// DisplayColumn returns the cursor column within the current line,
+// counting wide characters (like CJK) as two columns
+func (d *Document) DisplayColumn() (int, error) {
+	if d == nil || d.parser == nil || d.parser.module == nil {
+		return 0, fmt.Errorf("document is nil or closed")
+	}
+
+	displayColFn := d.parser.module.ExportedFunction("document_display_column")
+	if displayColFn == nil {
+		return 0, fmt.Errorf("WASM module does not export 'document_display_column' function")
+	}
+
+	results, err := displayColFn.Call(d.parser.ctx, uint64(d.documentID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get display column: %w", err)
+	}
+
+	return int(results[0]), nil
+}
+
+// defaultIndentWidth is reported by DetectIndent when the text has no indented lines
+const defaultIndentWidth = 4
+
+// DetectIndent infers the prevailing indentation style of the document. useTabs is
+// true when more indented lines start with a tab than with a space; width is then 1
+// (one tab per level). For spaces, width is the most common change in indentation
+// between consecutive indented lines, preferring the smaller width on ties. Without
+// any indented lines it reports spaces with a width of 4.
+func (d *Document) DetectIndent() (useTabs bool, width int, err error) {
+	text, err := d.Text()
+	if err != nil {
+		return false, 0, err
+	}
+
+	tabLines, spaceLines := 0, 0
+	deltas := make(map[int]int)
+	previous := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue // Blank lines say nothing about indentation
+		}
+
+		switch line[0] {
+		case '\t':
+			tabLines++
+		case ' ':
+			spaceLines++
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if delta := indent - previous; delta != 0 {
+			deltas[max(delta, -delta)]++
+		}
+		previous = indent
+	}
+
+	if tabLines > spaceLines {
+		return true, 1, nil
+	}
+
+	width = defaultIndentWidth
+	best := 0
+	for delta, count := range deltas {
+		if count > best || (count == best && delta < width) {
+			width, best = delta, count
+		}
+	}
+	return false, width, nil
+}
+
 // ToWasmState serializes the document state for WASM interop
 func (d *Document) ToWasmState() (*WasmDocumentState, error) {
 	if d == nil || d.parser == nil || d.parser.module == nil {
@@ -865,3 +1957,61 @@ func (d *Document) Close() error {
 	d.parser = nil
 	return nil
 }
+
+// CloseDocuments releases documents created by the same parser using a single
+// WASM call. Nil and already closed documents are skipped, so it pairs with
+// NewDocumentsWithText but accepts any mix of documents from that parser.
+// An error is returned if any document's ID no longer exists in the module;
+// the remaining documents are still released.
+func (p *KeyParser) CloseDocuments(documents []*Document) error {
+	if p == nil || p.module == nil {
+		return fmt.Errorf("parser is nil or closed")
+	}
+
+	ids := make([]uint32, 0, len(documents))
+	for _, d := range documents {
+		if d == nil || d.parser == nil {
+			continue
+		}
+		if d.parser != p {
+			return fmt.Errorf("document belongs to a different parser")
+		}
+		ids = append(ids, d.documentID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	destroyDocumentsFn := p.module.ExportedFunction("destroy_documents")
+	if destroyDocumentsFn == nil {
+		return fmt.Errorf("WASM module does not export 'destroy_documents' function")
+	}
+
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document IDs: %w", err)
+	}
+
+	idsPtr, err := p.allocateString(string(idsJSON))
+	if err != nil {
+		return err
+	}
+	defer p.freeMemory(idsPtr)
+
+	results, err := destroyDocumentsFn.Call(p.ctx, uint64(idsPtr), uint64(len(idsJSON)))
+	if err != nil {
+		return fmt.Errorf("failed to destroy documents: %w", err)
+	}
+
+	// Mark as closed. Documents that were found are destroyed even when
+	// others were missing, so this applies to the whole batch either way.
+	for _, d := range documents {
+		if d != nil {
+			d.parser = nil
+		}
+	}
+	if results[0] != 0 {
+		return fmt.Errorf("failed to destroy documents: document not found")
+	}
+	return nil
+}
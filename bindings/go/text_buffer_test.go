@@ -2,7 +2,9 @@ package keyparsing
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestTextBufferIntegration(t *testing.T) {
@@ -290,6 +292,1461 @@ func TestMultilineOperations(t *testing.T) {
 	}
 }
 
+func TestBufferInsertMultiline(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name        string
+		initial     string
+		cursor      int
+		insert      string
+		expected    string
+		expectedPos int
+		expectedRow int
+		expectedCol int
+	}{
+		{"empty buffer", "", 0, "a\nb\nc", "a\nb\nc", 5, 2, 1},
+		{"middle of text", "hello world", 5, "a\nb\nc", "helloa\nb\nc world", 10, 2, 1},
+		{"carriage returns", "", 0, "a\r\nb\rc", "a\nb\nc", 5, 2, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.initial); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.cursor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+
+			if err := buffer.InsertMultiline(tc.insert); err != nil {
+				t.Fatalf("Failed to insert multiline text: %v", err)
+			}
+
+			text, err := buffer.Text()
+			if err != nil {
+				t.Fatalf("Failed to get text: %v", err)
+			}
+			if text != tc.expected {
+				t.Errorf("Expected %q, got: %q", tc.expected, text)
+			}
+
+			pos, err := buffer.CursorPosition()
+			if err != nil {
+				t.Fatalf("Failed to get cursor position: %v", err)
+			}
+			if pos != tc.expectedPos {
+				t.Errorf("Expected cursor position %d, got: %d", tc.expectedPos, pos)
+			}
+
+			doc, err := buffer.Document()
+			if err != nil {
+				t.Fatalf("Failed to get document: %v", err)
+			}
+			defer doc.Close()
+
+			row, err := doc.CursorPositionRow()
+			if err != nil {
+				t.Fatalf("Failed to get cursor row: %v", err)
+			}
+			col, err := doc.CursorPositionCol()
+			if err != nil {
+				t.Fatalf("Failed to get cursor column: %v", err)
+			}
+			if row != tc.expectedRow || col != tc.expectedCol {
+				t.Errorf("Expected cursor at (%d, %d), got: (%d, %d)", tc.expectedRow, tc.expectedCol, row, col)
+			}
+		})
+	}
+}
+
+func TestBufferCaseConversion(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	// Uppercase only a range containing non-ASCII letters
+	err = buffer.InsertText("grüße aus köln", false, true)
+	if err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+
+	err = buffer.UppercaseRange(0, 5)
+	if err != nil {
+		t.Fatalf("Failed to uppercase range: %v", err)
+	}
+
+	text, err := buffer.Text()
+	if err != nil {
+		t.Fatalf("Failed to get text: %v", err)
+	}
+	if text != "GRÜSSE aus köln" {
+		t.Errorf("Expected 'GRÜSSE aus köln', got: %q", text)
+	}
+
+	// "ß" became "SS", so the cursor at the end moves one rune further
+	pos, err := buffer.CursorPosition()
+	if err != nil {
+		t.Fatalf("Failed to get cursor position: %v", err)
+	}
+	if pos != 15 {
+		t.Errorf("Expected cursor position 15, got: %d", pos)
+	}
+
+	// Negative indices are rejected rather than wrapping around
+	if err := buffer.UppercaseRange(-1, 3); err == nil {
+		t.Error("Expected error for a negative range start")
+	}
+	if err := buffer.LowercaseRange(0, -1); err == nil {
+		t.Error("Expected error for a negative range end")
+	}
+	if text, _ := buffer.Text(); text != "GRÜSSE aus köln" {
+		t.Errorf("Expected text unchanged after invalid ranges, got: %q", text)
+	}
+
+	// Whole-buffer variants
+	err = buffer.UppercaseText()
+	if err != nil {
+		t.Fatalf("Failed to uppercase text: %v", err)
+	}
+
+	text, err = buffer.Text()
+	if err != nil {
+		t.Fatalf("Failed to get text: %v", err)
+	}
+	if text != "GRÜSSE AUS KÖLN" {
+		t.Errorf("Expected 'GRÜSSE AUS KÖLN', got: %q", text)
+	}
+
+	err = buffer.LowercaseText()
+	if err != nil {
+		t.Fatalf("Failed to lowercase text: %v", err)
+	}
+
+	text, err = buffer.Text()
+	if err != nil {
+		t.Fatalf("Failed to get text: %v", err)
+	}
+	if text != "grüsse aus köln" {
+		t.Errorf("Expected 'grüsse aus köln', got: %q", text)
+	}
+
+	// A copied handle whose buffer was destroyed reports the failure
+	stale := *buffer
+	buffer.Close()
+	if err := stale.UppercaseRange(0, 1); err == nil {
+		t.Error("Expected error converting a range in a destroyed buffer")
+	}
+	if err := stale.LowercaseRange(0, 1); err == nil {
+		t.Error("Expected error converting a range in a destroyed buffer")
+	}
+}
+
+func TestDisplayColumn(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	// Cursor after "漢字a" on the second line
+	doc, err := parser.NewDocumentWithText("first\n漢字ab", 9)
+	if err != nil {
+		t.Fatalf("Failed to create document: %v", err)
+	}
+	defer doc.Close()
+
+	col, err := doc.CursorPositionCol()
+	if err != nil {
+		t.Fatalf("Failed to get cursor column: %v", err)
+	}
+	displayCol, err := doc.DisplayColumn()
+	if err != nil {
+		t.Fatalf("Failed to get display column: %v", err)
+	}
+	if col != 3 {
+		t.Errorf("Expected cursor column 3, got: %d", col)
+	}
+	if displayCol != 5 {
+		t.Errorf("Expected display column 5, got: %d", displayCol)
+	}
+	if displayCol <= col {
+		t.Errorf("Expected display column %d to exceed rune column %d", displayCol, col)
+	}
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	if err := buffer.SetText("first\n漢字ab"); err != nil {
+		t.Fatalf("Failed to set text: %v", err)
+	}
+	if err := buffer.SetCursorPosition(9); err != nil {
+		t.Fatalf("Failed to set cursor position: %v", err)
+	}
+
+	bufferCol, err := buffer.DisplayColumnAtCursor()
+	if err != nil {
+		t.Fatalf("Failed to get display column at cursor: %v", err)
+	}
+	if bufferCol != 5 {
+		t.Errorf("Expected display column 5, got: %d", bufferCol)
+	}
+}
+
+func TestDocumentCurrentWord(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name   string
+		text   string
+		cursor int
+		word   string
+		start  int
+		end    int
+	}{
+		{"middle of word", "hello", 2, "hello", 0, 5},
+		{"end of word", "hello world", 11, "world", 6, 11},
+		{"on whitespace", "hello   world", 6, "", 6, 6},
+		{"wide characters", "ls こんにちは", 5, "こんにちは", 3, 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parser.NewDocumentWithText(tc.text, tc.cursor)
+			if err != nil {
+				t.Fatalf("Failed to create document: %v", err)
+			}
+			defer doc.Close()
+
+			word, start, end, err := doc.CurrentWord()
+			if err != nil {
+				t.Fatalf("Failed to get current word: %v", err)
+			}
+			if word != tc.word || start != tc.start || end != tc.end {
+				t.Errorf("Expected (%q, %d, %d), got: (%q, %d, %d)", tc.word, tc.start, tc.end, word, start, end)
+			}
+		})
+	}
+}
+
+func TestDocumentCurrentLineSplit(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name   string
+		text   string
+		cursor int
+		before string
+		after  string
+	}{
+		{"middle line mid-line", "first\nsecond line\nthird", 9, "sec", "ond line"},
+		{"start of line", "first\nsecond\nthird", 6, "", "second"},
+		{"end of line", "first\nsecond\nthird", 12, "second", ""},
+		{"last line", "first\nsecond\nthird", 15, "th", "ird"},
+		{"single line", "hello world", 5, "hello", " world"},
+		{"multibyte", "ls\nこんにちは", 5, "こん", "にちは"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parser.NewDocumentWithText(tc.text, tc.cursor)
+			if err != nil {
+				t.Fatalf("Failed to create document: %v", err)
+			}
+			defer doc.Close()
+
+			before, after, err := doc.CurrentLineSplit()
+			if err != nil {
+				t.Fatalf("Failed to get current line split: %v", err)
+			}
+			if before != tc.before || after != tc.after {
+				t.Errorf("Expected (%q, %q), got: (%q, %q)", tc.before, tc.after, before, after)
+			}
+		})
+	}
+}
+
+func TestBufferDeleteWordBeforeCursor(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		cursor    int
+		deleted   string
+		remaining string
+		newCursor int
+	}{
+		{"multiple spaces", "hello   world   ", 16, "world   ", "hello   ", 8},
+		{"punctuation", "cd /usr/local", 13, "local", "cd /usr/", 8},
+		{"cjk text", "こんにちは 世界", 8, "世界", "こんにちは ", 6},
+		{"cursor at zero", "hello", 0, "", "hello", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.text); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.cursor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+
+			deleted, err := buffer.DeleteWordBeforeCursor()
+			if err != nil {
+				t.Fatalf("Failed to delete word: %v", err)
+			}
+			if deleted != tc.deleted {
+				t.Errorf("Expected deleted text %q, got: %q", tc.deleted, deleted)
+			}
+
+			text, _ := buffer.Text()
+			if text != tc.remaining {
+				t.Errorf("Expected text %q, got: %q", tc.remaining, text)
+			}
+			pos, _ := buffer.CursorPosition()
+			if pos != tc.newCursor {
+				t.Errorf("Expected cursor %d, got: %d", tc.newCursor, pos)
+			}
+		})
+	}
+}
+
+func TestBufferDeleteWordAfterCursor(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		cursor    int
+		deleted   string
+		remaining string
+	}{
+		{"leading whitespace", "git   commit -m", 3, "   commit", "git -m"},
+		{"trailing whitespace", "hello   ", 5, "   ", "hello"},
+		{"end of buffer", "hello", 5, "", "hello"},
+		{"unicode boundaries", "日本語 テキスト", 3, " テキスト", "日本語"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.text); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.cursor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+
+			deleted, err := buffer.DeleteWordAfterCursor()
+			if err != nil {
+				t.Fatalf("Failed to delete word: %v", err)
+			}
+			if deleted != tc.deleted {
+				t.Errorf("Expected deleted text %q, got: %q", tc.deleted, deleted)
+			}
+
+			text, _ := buffer.Text()
+			if text != tc.remaining {
+				t.Errorf("Expected text %q, got: %q", tc.remaining, text)
+			}
+			pos, _ := buffer.CursorPosition()
+			if pos != tc.cursor {
+				t.Errorf("Expected cursor to stay at %d, got: %d", tc.cursor, pos)
+			}
+		})
+	}
+}
+
+func TestBufferSelectionCase(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		anchor    int
+		cursor    int
+		transform func(*Buffer) error
+		expected  string
+		selected  string
+	}{
+		{"uppercase sharp s", "die straße hier", 4, 10, (*Buffer).UppercaseSelection, "die STRASSE hier", "STRASSE"},
+		{"uppercase anchor after cursor", "grüße aus köln", 5, 0, (*Buffer).UppercaseSelection, "GRÜSSE aus köln", "GRÜSSE"},
+		{"lowercase umlauts", "ÄRGER ÜBER ÖL", 6, 13, (*Buffer).LowercaseSelection, "ÄRGER über öl", "über öl"},
+		{"empty selection", "straße", 3, 3, (*Buffer).UppercaseSelection, "straße", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.text); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.anchor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+			if err := buffer.SetSelectionAnchor(); err != nil {
+				t.Fatalf("Failed to set selection anchor: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.cursor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+
+			if err := tc.transform(buffer); err != nil {
+				t.Fatalf("Failed to transform selection: %v", err)
+			}
+
+			text, _ := buffer.Text()
+			if text != tc.expected {
+				t.Errorf("Expected text %q, got: %q", tc.expected, text)
+			}
+			if !buffer.HasSelection() {
+				t.Error("Expected selection to be kept")
+			}
+			selected, err := buffer.GetSelectedText()
+			if err != nil {
+				t.Fatalf("Failed to get selected text: %v", err)
+			}
+			if selected != tc.selected {
+				t.Errorf("Expected selection %q, got: %q", tc.selected, selected)
+			}
+		})
+	}
+}
+
+func TestBufferUndoRedo(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+
+	assertState := func(step, text string, cursor int) {
+		t.Helper()
+		gotText, err := buffer.Text()
+		if err != nil {
+			t.Fatalf("%s: failed to get text: %v", step, err)
+		}
+		gotCursor, err := buffer.CursorPosition()
+		if err != nil {
+			t.Fatalf("%s: failed to get cursor position: %v", step, err)
+		}
+		if gotText != text || gotCursor != cursor {
+			t.Errorf("%s: expected (%q, %d), got: (%q, %d)", step, text, cursor, gotText, gotCursor)
+		}
+	}
+
+	// Undo with empty history is a no-op
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertState("empty undo", "", 0)
+
+	if err := buffer.InsertText("hello", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	if err := buffer.InsertText(" world", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	if _, err := buffer.DeleteBeforeCursor(3); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	assertState("after edits", "hello wo", 8)
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertState("undo delete", "hello world", 11)
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertState("undo insert", "hello", 5)
+
+	if err := buffer.Redo(); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	assertState("redo insert", "hello world", 11)
+
+	// A new edit clears the redo history
+	if err := buffer.SwapCharactersBeforeCursor(); err != nil {
+		t.Fatalf("Failed to swap characters: %v", err)
+	}
+	assertState("swap", "hello wordl", 11)
+	if err := buffer.Redo(); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	assertState("redo after edit", "hello wordl", 11)
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertState("undo swap", "hello world", 11)
+
+	// Deleting nothing does not add an undo step
+	if err := buffer.SetCursorPosition(0); err != nil {
+		t.Fatalf("Failed to set cursor position: %v", err)
+	}
+	if _, err := buffer.DeleteBeforeCursor(1); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	assertState("undo after no-op delete", "hello", 5)
+}
+
+func TestBufferUndoDisabledByDefault(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	if err := buffer.InsertText("hello", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if text, _ := buffer.Text(); text != "hello" {
+		t.Errorf("Expected undo to do nothing without history, got: %q", text)
+	}
+
+	// Turning undo off again drops what was recorded
+	buffer.SetUndoEnabled(true)
+	if err := buffer.InsertText(" world", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	buffer.SetUndoEnabled(false)
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if text, _ := buffer.Text(); text != "hello world" {
+		t.Errorf("Expected history to be dropped, got: %q", text)
+	}
+}
+
+func TestBufferUndoCoalescing(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+	buffer.SetUndoCoalescing(true)
+	for _, ch := range []string{"a", "b", "c"} {
+		if err := buffer.InsertText(ch, false, true); err != nil {
+			t.Fatalf("Failed to insert text: %v", err)
+		}
+	}
+
+	// Moving the cursor starts a new typing run
+	if err := buffer.CursorLeft(1); err != nil {
+		t.Fatalf("Failed to move cursor: %v", err)
+	}
+	for _, ch := range []string{"x", "y"} {
+		if err := buffer.InsertText(ch, false, true); err != nil {
+			t.Fatalf("Failed to insert text: %v", err)
+		}
+	}
+
+	text, _ := buffer.Text()
+	if text != "abxyc" {
+		t.Fatalf("Expected text 'abxyc', got: %q", text)
+	}
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	text, _ = buffer.Text()
+	if text != "abc" {
+		t.Errorf("Expected first undo to remove 'xy', got: %q", text)
+	}
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	text, _ = buffer.Text()
+	if text != "" {
+		t.Errorf("Expected second undo to remove 'abc', got: %q", text)
+	}
+}
+
+func TestBufferApplyEdits(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+
+	err = buffer.ApplyEdits([]BufferEdit{
+		{Op: EditInsert, Text: "hello"},
+		{Op: EditCursorLeft, Count: 2},
+		{Op: EditDeleteBeforeCursor, Count: 1},
+		{Op: EditInsert, Text: "X"},
+		{Op: EditSetCursorPosition, Position: 0},
+		{Op: EditDelete, Count: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply edits: %v", err)
+	}
+
+	text, _ := buffer.Text()
+	if text != "eXlo" {
+		t.Errorf("Expected 'eXlo', got: %q", text)
+	}
+	pos, _ := buffer.CursorPosition()
+	if pos != 0 {
+		t.Errorf("Expected cursor at 0, got: %d", pos)
+	}
+
+	// An unknown operation rejects the whole batch
+	err = buffer.ApplyEdits([]BufferEdit{
+		{Op: EditInsert, Text: "ignored"},
+		{Op: "bogus"},
+	})
+	if err == nil {
+		t.Error("Expected error for unknown edit operation")
+	}
+	text, _ = buffer.Text()
+	if text != "eXlo" {
+		t.Errorf("Expected text to be unchanged after a rejected batch, got: %q", text)
+	}
+
+	// The whole batch is a single undo step
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	text, _ = buffer.Text()
+	if text != "" {
+		t.Errorf("Expected undo to revert the batch, got: %q", text)
+	}
+}
+
+func TestBufferSelection(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		anchor    int
+		cursor    int
+		selected  string
+		remaining string
+	}{
+		{"anchor before cursor", "hello world", 0, 5, "hello", " world"},
+		{"anchor after cursor", "hello world", 11, 6, "world", "hello "},
+		{"multiline", "one\ntwo\nthree", 2, 9, "e\ntwo\nt", "onhree"},
+		{"empty selection", "hello", 3, 3, "", "hello"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.text); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.anchor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+			if err := buffer.SetSelectionAnchor(); err != nil {
+				t.Fatalf("Failed to set selection anchor: %v", err)
+			}
+			if err := buffer.SetCursorPosition(tc.cursor); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+
+			selected, err := buffer.GetSelectedText()
+			if err != nil {
+				t.Fatalf("Failed to get selected text: %v", err)
+			}
+			if selected != tc.selected {
+				t.Errorf("Expected selection %q, got: %q", tc.selected, selected)
+			}
+
+			deleted, err := buffer.DeleteSelection()
+			if err != nil {
+				t.Fatalf("Failed to delete selection: %v", err)
+			}
+			if deleted != tc.selected {
+				t.Errorf("Expected deleted text %q, got: %q", tc.selected, deleted)
+			}
+
+			text, _ := buffer.Text()
+			if text != tc.remaining {
+				t.Errorf("Expected text %q, got: %q", tc.remaining, text)
+			}
+			pos, _ := buffer.CursorPosition()
+			if expected := min(tc.anchor, tc.cursor); pos != expected {
+				t.Errorf("Expected cursor collapsed to %d, got: %d", expected, pos)
+			}
+			if buffer.HasSelection() {
+				t.Error("Expected selection to be cleared after DeleteSelection")
+			}
+		})
+	}
+}
+
+func TestDocumentDetectIndent(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name    string
+		text    string
+		useTabs bool
+		width   int
+	}{
+		{"four spaces", "def f():\n    if x:\n        return 1\n\n    return 2\n", false, 4},
+		{"two spaces", "a:\n  b:\n    c: 1\n  d: 2\n", false, 2},
+		{"tabs", "func f() {\n\tif x {\n\t\treturn\n\t}\n}\n", true, 1},
+		{"no indentation", "select 1;\nselect 2;", false, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parser.NewDocumentWithText(tc.text, 0)
+			if err != nil {
+				t.Fatalf("Failed to create document: %v", err)
+			}
+			defer doc.Close()
+
+			useTabs, width, err := doc.DetectIndent()
+			if err != nil {
+				t.Fatalf("Failed to detect indent: %v", err)
+			}
+			if useTabs != tc.useTabs || width != tc.width {
+				t.Errorf("Expected (useTabs=%v, width=%d), got: (useTabs=%v, width=%d)", tc.useTabs, tc.width, useTabs, width)
+			}
+		})
+	}
+}
+
+func TestTextLengths(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name    string
+		text    string
+		byteLen int
+		runeLen int
+	}{
+		{"empty", "", 0, 0},
+		{"ascii", "hello", 5, 5},
+		{"multibyte", "héllo 世界", 13, 8},
+		{"emoji", "hi 🚀", 7, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			if err := buffer.SetText(tc.text); err != nil {
+				t.Fatalf("Failed to set text: %v", err)
+			}
+
+			byteLen, err := buffer.ByteLen()
+			if err != nil {
+				t.Fatalf("Failed to get buffer byte length: %v", err)
+			}
+			runeLen, err := buffer.RuneLen()
+			if err != nil {
+				t.Fatalf("Failed to get buffer rune length: %v", err)
+			}
+			if byteLen != tc.byteLen || runeLen != tc.runeLen {
+				t.Errorf("Expected buffer lengths (bytes=%d, runes=%d), got: (bytes=%d, runes=%d)", tc.byteLen, tc.runeLen, byteLen, runeLen)
+			}
+
+			// Cursor positions are rune indices, so a byte offset past the end clamps to RuneLen
+			if err := buffer.SetCursorPosition(tc.byteLen); err != nil {
+				t.Fatalf("Failed to set cursor position: %v", err)
+			}
+			pos, _ := buffer.CursorPosition()
+			if pos != tc.runeLen {
+				t.Errorf("Expected cursor at rune length %d, got: %d", tc.runeLen, pos)
+			}
+
+			doc, err := parser.NewDocumentWithText(tc.text, 0)
+			if err != nil {
+				t.Fatalf("Failed to create document: %v", err)
+			}
+			defer doc.Close()
+
+			byteLen, err = doc.ByteLen()
+			if err != nil {
+				t.Fatalf("Failed to get document byte length: %v", err)
+			}
+			runeLen, err = doc.RuneLen()
+			if err != nil {
+				t.Fatalf("Failed to get document rune length: %v", err)
+			}
+			if byteLen != tc.byteLen || runeLen != tc.runeLen {
+				t.Errorf("Expected document lengths (bytes=%d, runes=%d), got: (bytes=%d, runes=%d)", tc.byteLen, tc.runeLen, byteLen, runeLen)
+			}
+
+			// A copied handle whose ID was destroyed reports the failure
+			staleBuffer, staleDoc := *buffer, *doc
+			buffer.Close()
+			doc.Close()
+			if _, err := staleBuffer.ByteLen(); err == nil {
+				t.Error("Expected error getting the byte length of a destroyed buffer")
+			}
+			if _, err := staleDoc.ByteLen(); err == nil {
+				t.Error("Expected error getting the byte length of a destroyed document")
+			}
+		})
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	assertEmpty := func(step string, expected bool) {
+		t.Helper()
+		empty, err := buffer.IsEmpty()
+		if err != nil {
+			t.Fatalf("%s: failed to check buffer: %v", step, err)
+		}
+		if empty != expected {
+			t.Errorf("%s: expected buffer empty=%v, got: %v", step, expected, empty)
+		}
+
+		doc, err := buffer.Document()
+		if err != nil {
+			t.Fatalf("%s: failed to get document: %v", step, err)
+		}
+		defer doc.Close()
+		empty, err = doc.IsEmpty()
+		if err != nil {
+			t.Fatalf("%s: failed to check document: %v", step, err)
+		}
+		if empty != expected {
+			t.Errorf("%s: expected document empty=%v, got: %v", step, expected, empty)
+		}
+	}
+
+	assertEmpty("new buffer", true)
+
+	if err := buffer.InsertText("héllo", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	assertEmpty("after insert", false)
+
+	if _, err := buffer.DeleteBeforeCursor(5); err != nil {
+		t.Fatalf("Failed to delete text: %v", err)
+	}
+	assertEmpty("after full delete", true)
+
+	// A copied handle keeps the ID after the original is closed
+	doc, err := buffer.Document()
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	staleBuffer, staleDoc := *buffer, *doc
+
+	buffer.Close()
+	doc.Close()
+	if _, err := buffer.IsEmpty(); err == nil {
+		t.Error("Expected error checking a closed buffer")
+	}
+	if _, err := doc.IsEmpty(); err == nil {
+		t.Error("Expected error checking a closed document")
+	}
+	if _, err := staleBuffer.IsEmpty(); err == nil {
+		t.Error("Expected error checking a buffer whose ID was destroyed")
+	}
+	if _, err := staleDoc.IsEmpty(); err == nil {
+		t.Error("Expected error checking a document whose ID was destroyed")
+	}
+}
+
+func TestBufferWordWrap(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+
+	paragraph := "The quick brown fox jumps over the lazy dog while the cat watches from the windowsill"
+	if err := buffer.SetText(paragraph); err != nil {
+		t.Fatalf("Failed to set text: %v", err)
+	}
+	if err := buffer.WordWrap(20); err != nil {
+		t.Fatalf("Failed to wrap text: %v", err)
+	}
+
+	text, _ := buffer.Text()
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected text to wrap onto several lines, got: %q", text)
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > 20 {
+			t.Errorf("Expected line width <= 20, got %d: %q", n, line)
+		}
+		if line != strings.TrimSpace(line) {
+			t.Errorf("Expected line to break at a word boundary, got: %q", line)
+		}
+	}
+	if joined := strings.Join(lines, " "); joined != paragraph {
+		t.Errorf("Expected wrapped words %q, got: %q", paragraph, joined)
+	}
+
+	// Paragraph breaks are preserved
+	if err := buffer.SetText("first paragraph here\n\nsecond one"); err != nil {
+		t.Fatalf("Failed to set text: %v", err)
+	}
+	if err := buffer.WordWrap(10); err != nil {
+		t.Fatalf("Failed to wrap text: %v", err)
+	}
+	text, _ = buffer.Text()
+	if expected := "first\nparagraph\nhere\n\nsecond one"; text != expected {
+		t.Errorf("Expected %q, got: %q", expected, text)
+	}
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	text, _ = buffer.Text()
+	if text != "first paragraph here\n\nsecond one" {
+		t.Errorf("Expected undo to restore unwrapped text, got: %q", text)
+	}
+}
+
+func TestBufferCopy(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	original, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer original.Close()
+
+	original.SetUndoEnabled(true)
+
+	if err := original.InsertText("hello world", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	if err := original.SetCursorPosition(5); err != nil {
+		t.Fatalf("Failed to set cursor position: %v", err)
+	}
+
+	copied, err := original.Copy()
+	if err != nil {
+		t.Fatalf("Failed to copy buffer: %v", err)
+	}
+	defer copied.Close()
+
+	text, _ := copied.Text()
+	pos, _ := copied.CursorPosition()
+	if text != "hello world" || pos != 5 {
+		t.Errorf("Expected copy (\"hello world\", 5), got: (%q, %d)", text, pos)
+	}
+
+	// Mutating the copy leaves the original untouched
+	if err := copied.InsertText(",", false, true); err != nil {
+		t.Fatalf("Failed to insert text: %v", err)
+	}
+	if _, err := copied.Delete(6); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	text, _ = copied.Text()
+	if text != "hello," {
+		t.Errorf("Expected copy text %q, got: %q", "hello,", text)
+	}
+	text, _ = original.Text()
+	pos, _ = original.CursorPosition()
+	if text != "hello world" || pos != 5 {
+		t.Errorf("Expected original unchanged (\"hello world\", 5), got: (%q, %d)", text, pos)
+	}
+
+	// Undo history is copied, not shared
+	if err := copied.Undo(); err != nil {
+		t.Fatalf("Failed to undo on copy: %v", err)
+	}
+	if err := original.Undo(); err != nil {
+		t.Fatalf("Failed to undo on original: %v", err)
+	}
+	text, _ = original.Text()
+	if text != "" {
+		t.Errorf("Expected original undo to remove the insert, got: %q", text)
+	}
+	text, _ = copied.Text()
+	if text != "hello, world" {
+		t.Errorf("Expected copy undo to restore %q, got: %q", "hello, world", text)
+	}
+}
+
+func TestNewDocumentsWithText(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	specs := []DocumentSpec{
+		{Text: "git commit", Cursor: 3},
+		{Text: "", Cursor: 0},
+		{Text: "こんにちは", Cursor: 2},
+	}
+	docs, err := parser.NewDocumentsWithText(specs)
+	if err != nil {
+		t.Fatalf("Failed to create documents: %v", err)
+	}
+	if len(docs) != len(specs) {
+		t.Fatalf("Expected %d documents, got: %d", len(specs), len(docs))
+	}
+
+	for i, spec := range specs {
+		text, err := docs[i].Text()
+		if err != nil {
+			t.Fatalf("Failed to get text: %v", err)
+		}
+		pos, err := docs[i].CursorPosition()
+		if err != nil {
+			t.Fatalf("Failed to get cursor position: %v", err)
+		}
+		if text != spec.Text || pos != spec.Cursor {
+			t.Errorf("Document %d: expected (%q, %d), got: (%q, %d)", i, spec.Text, spec.Cursor, text, pos)
+		}
+	}
+
+	// A single document closed early is skipped by the batch close
+	if err := docs[1].Close(); err != nil {
+		t.Fatalf("Failed to close document: %v", err)
+	}
+	if err := parser.CloseDocuments(docs); err != nil {
+		t.Fatalf("Failed to close documents: %v", err)
+	}
+	if _, err := docs[0].Text(); err == nil {
+		t.Error("Expected error using a closed document")
+	}
+
+	// A copied handle whose ID was already destroyed is reported
+	docs, err = parser.NewDocumentsWithText(specs[:2])
+	if err != nil {
+		t.Fatalf("Failed to create documents: %v", err)
+	}
+	stale := *docs[0]
+	if err := docs[0].Close(); err != nil {
+		t.Fatalf("Failed to close document: %v", err)
+	}
+	if err := parser.CloseDocuments([]*Document{&stale, docs[1]}); err == nil {
+		t.Error("Expected error closing a document whose ID was destroyed")
+	}
+	if _, err := docs[1].Text(); err == nil {
+		t.Error("Expected the rest of the batch to be closed")
+	}
+
+	if docs, err := parser.NewDocumentsWithText(nil); err != nil || docs != nil {
+		t.Errorf("Expected nil result for an empty batch, got: %v, %v", docs, err)
+	}
+}
+
+func TestBufferSubwordMode(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetUndoEnabled(true)
+
+	if err := buffer.SetText("getFooBar get_foo_bar"); err != nil {
+		t.Fatalf("Failed to set text: %v", err)
+	}
+	if err := buffer.SetCursorPosition(0); err != nil {
+		t.Fatalf("Failed to set cursor position: %v", err)
+	}
+
+	// Whole words by default
+	if err := buffer.CursorWordRight(); err != nil {
+		t.Fatalf("Failed to move right: %v", err)
+	}
+	if pos, _ := buffer.CursorPosition(); pos != 9 {
+		t.Errorf("Expected cursor at 9, got: %d", pos)
+	}
+
+	buffer.SetSubwordMode(true)
+	for _, expected := range []int{6, 3, 0} {
+		if err := buffer.CursorWordLeft(); err != nil {
+			t.Fatalf("Failed to move left: %v", err)
+		}
+		if pos, _ := buffer.CursorPosition(); pos != expected {
+			t.Errorf("Expected cursor at %d, got: %d", expected, pos)
+		}
+	}
+	for _, expected := range []int{3, 6, 9, 13, 17, 21} {
+		if err := buffer.CursorWordRight(); err != nil {
+			t.Fatalf("Failed to move right: %v", err)
+		}
+		if pos, _ := buffer.CursorPosition(); pos != expected {
+			t.Errorf("Expected cursor at %d, got: %d", expected, pos)
+		}
+	}
+
+	// Deletions follow the same boundaries and stay undoable
+	deleted, err := buffer.DeleteWordBeforeCursor()
+	if err != nil {
+		t.Fatalf("Failed to delete word: %v", err)
+	}
+	if deleted != "bar" {
+		t.Errorf("Expected %q deleted, got: %q", "bar", deleted)
+	}
+	buffer.SetCursorPosition(3)
+	deleted, err = buffer.DeleteWordAfterCursor()
+	if err != nil {
+		t.Fatalf("Failed to delete word: %v", err)
+	}
+	if deleted != "Foo" {
+		t.Errorf("Expected %q deleted, got: %q", "Foo", deleted)
+	}
+	text, _ := buffer.Text()
+	if text != "getBar get_foo_" {
+		t.Errorf("Expected %q, got: %q", "getBar get_foo_", text)
+	}
+
+	if err := buffer.Undo(); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	text, _ = buffer.Text()
+	if text != "getFooBar get_foo_" {
+		t.Errorf("Expected undo to restore %q, got: %q", "getFooBar get_foo_", text)
+	}
+}
+
+func TestBufferWordCase(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		cursor    int
+		transform func(*Buffer) error
+		expected  string
+		newCursor int
+	}{
+		{"uppercase", "git commit", 3, (*Buffer).UppercaseWord, "git COMMIT", 10},
+		{"lowercase mid-word", "HELLO WORLD", 2, (*Buffer).LowercaseWord, "HEllo WORLD", 5},
+		{"capitalize", "hello wORLD", 5, (*Buffer).CapitalizeWord, "hello World", 11},
+		{"uppercase sharp s", "maß", 0, (*Buffer).UppercaseWord, "MASS", 4},
+		{"capitalize sharp s", "ßtraße", 0, (*Buffer).CapitalizeWord, "Sstraße", 7},
+		{"uppercase dotless i", "ılık", 0, (*Buffer).UppercaseWord, "ILIK", 4},
+		{"lowercase dotted I", "İSTANBUL", 0, (*Buffer).LowercaseWord, "i\u0307stanbul", 9},
+		{"nothing after cursor", "done", 4, (*Buffer).UppercaseWord, "done", 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			buffer.SetUndoEnabled(true)
+
+			buffer.SetText(tc.text)
+			buffer.SetCursorPosition(tc.cursor)
+
+			if err := tc.transform(buffer); err != nil {
+				t.Fatalf("Failed to transform word: %v", err)
+			}
+			text, _ := buffer.Text()
+			pos, _ := buffer.CursorPosition()
+			if text != tc.expected || pos != tc.newCursor {
+				t.Errorf("Expected (%q, %d), got: (%q, %d)", tc.expected, tc.newCursor, text, pos)
+			}
+
+			if err := buffer.Undo(); err != nil {
+				t.Fatalf("Failed to undo: %v", err)
+			}
+			if text, _ := buffer.Text(); text != tc.text {
+				t.Errorf("Expected undo to restore %q, got: %q", tc.text, text)
+			}
+		})
+	}
+}
+
+func TestBufferDeleteToLineBoundary(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	testCases := []struct {
+		name      string
+		text      string
+		cursor    int
+		toEnd     bool
+		deleted   string
+		expected  string
+		newCursor int
+	}{
+		{"to end mid-line", "hello world", 6, true, "world", "hello ", 6},
+		{"to end at line start", "hello world", 0, true, "hello world", "", 0},
+		{"to end at line end", "hello", 5, true, "", "hello", 5},
+		{"to start mid-line", "hello world", 6, false, "hello ", "world", 0},
+		{"to start at line start", "hello", 0, false, "", "hello", 0},
+		{"to start at line end", "hello", 5, false, "hello", "", 0},
+		{"to end stops at newline", "one\ntwo\nthree", 5, true, "wo", "one\nt\nthree", 5},
+		{"to end at end of middle line", "one\ntwo\nthree", 7, true, "", "one\ntwo\nthree", 7},
+		{"to start stops at newline", "one\ntwo\nthree", 6, false, "tw", "one\no\nthree", 4},
+		{"to start at start of middle line", "one\ntwo\nthree", 4, false, "", "one\ntwo\nthree", 4},
+		{"multibyte", "こんにちは 世界", 3, true, "ちは 世界", "こんに", 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, err := parser.NewBuffer()
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			defer buffer.Close()
+
+			buffer.SetText(tc.text)
+			buffer.SetCursorPosition(tc.cursor)
+
+			var deleted string
+			if tc.toEnd {
+				deleted, err = buffer.DeleteToLineEnd()
+			} else {
+				deleted, err = buffer.DeleteToLineStart()
+			}
+			if err != nil {
+				t.Fatalf("Failed to delete: %v", err)
+			}
+
+			text, _ := buffer.Text()
+			pos, _ := buffer.CursorPosition()
+			if deleted != tc.deleted || text != tc.expected || pos != tc.newCursor {
+				t.Errorf("Expected (%q, %q, %d), got: (%q, %q, %d)", tc.deleted, tc.expected, tc.newCursor, deleted, text, pos)
+			}
+		})
+	}
+}
+
+func TestBufferDeleteBeforeCursorJoinsLines(t *testing.T) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	buffer.SetText("one\ntwo\nthree")
+	buffer.SetCursorPosition(4) // Start of "two"
+
+	deleted, err := buffer.DeleteBeforeCursor(1)
+	if err != nil {
+		t.Fatalf("Failed to delete before cursor: %v", err)
+	}
+	text, _ := buffer.Text()
+	pos, _ := buffer.CursorPosition()
+	if deleted != "\n" || text != "onetwo\nthree" || pos != 3 {
+		t.Errorf("Expected (%q, %q, 3), got: (%q, %q, %d)", "\n", "onetwo\nthree", deleted, text, pos)
+	}
+
+	doc, err := buffer.Document()
+	if err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	defer doc.Close()
+	row, _ := doc.CursorPositionRow()
+	col, _ := doc.CursorPositionCol()
+	if row != 0 || col != 3 {
+		t.Errorf("Expected cursor at row 0, col 3, got: row %d, col %d", row, col)
+	}
+
+	// A count spanning the boundary deletes across it in one call
+	buffer.SetCursorPosition(8)
+	deleted, _ = buffer.DeleteBeforeCursor(4)
+	text, _ = buffer.Text()
+	pos, _ = buffer.CursorPosition()
+	if deleted != "wo\nt" || text != "onethree" || pos != 4 {
+		t.Errorf("Expected (%q, %q, 4), got: (%q, %q, %d)", "wo\nt", "onethree", deleted, text, pos)
+	}
+
+	// Vertical movement after a join starts from the join column
+	buffer.SetText("abc\n\nxyz")
+	buffer.SetCursorPosition(2)
+	buffer.CursorDown(1)
+	buffer.DeleteBeforeCursor(1)
+	buffer.CursorDown(1)
+	if pos, _ := buffer.CursorPosition(); pos != 7 {
+		t.Errorf("Expected cursor at 7 after moving down from the join, got: %d", pos)
+	}
+}
+
 // Benchmark tests
 func BenchmarkBufferInsertText(b *testing.B) {
 	ctx := context.Background()
@@ -334,3 +1791,96 @@ func BenchmarkDocumentTextAnalysis(b *testing.B) {
 		doc.TextAfterCursor()
 	}
 }
+
+// benchmarkInsertCount is the number of single-character inserts per iteration
+// in the InsertText vs ApplyEdits benchmarks.
+const benchmarkInsertCount = 100
+
+func BenchmarkBufferInsertTextIndividually(b *testing.B) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		b.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		b.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.SetText("") // Reset for each iteration
+		for j := 0; j < benchmarkInsertCount; j++ {
+			buffer.InsertText("x", false, true)
+		}
+	}
+}
+
+func BenchmarkBufferApplyEdits(b *testing.B) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		b.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	buffer, err := parser.NewBuffer()
+	if err != nil {
+		b.Fatalf("Failed to create buffer: %v", err)
+	}
+	defer buffer.Close()
+
+	edits := make([]BufferEdit, benchmarkInsertCount)
+	for j := range edits {
+		edits[j] = BufferEdit{Op: EditInsert, Text: "x"}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.SetText("") // Reset for each iteration
+		buffer.ApplyEdits(edits)
+	}
+}
+
+// benchmarkDocumentCount is the number of documents created per iteration
+const benchmarkDocumentCount = 100
+
+func BenchmarkNewDocumentWithText(b *testing.B) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		b.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < benchmarkDocumentCount; j++ {
+			doc, _ := parser.NewDocumentWithText("git checkout --track", 12)
+			doc.Close()
+		}
+	}
+}
+
+func BenchmarkNewDocumentsWithText(b *testing.B) {
+	ctx := context.Background()
+	parser, err := New(ctx)
+	if err != nil {
+		b.Fatalf("Failed to create parser: %v", err)
+	}
+	defer parser.Close()
+
+	specs := make([]DocumentSpec, benchmarkDocumentCount)
+	for j := range specs {
+		specs[j] = DocumentSpec{Text: "git checkout --track", Cursor: 12}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		docs, _ := parser.NewDocumentsWithText(specs)
+		parser.CloseDocuments(docs)
+	}
+}
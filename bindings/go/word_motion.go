@@ -0,0 +1,66 @@
+package keyparsing
+
+import "unicode"
+
+// isWordRune reports whether r belongs to a word, matching the word deletions
+// in the WASM module: letters, digits and underscores.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
+
+// wordBoundary reports whether a word motion stops between chars[i-1] and
+// chars[i]. Runs of word runes and runs of other non-space runes are separate
+// words; in subword mode underscores and case changes split words further.
+func wordBoundary(chars []rune, i int, subword bool) bool {
+	prev, cur := chars[i-1], chars[i]
+	if isWordRune(prev) != isWordRune(cur) {
+		return true
+	}
+	if !subword {
+		return false
+	}
+	if (prev == '_') != (cur == '_') {
+		return true
+	}
+	if (unicode.IsLower(prev) || unicode.IsNumber(prev)) && unicode.IsUpper(cur) {
+		return true
+	}
+	// The last capital of an acronym starts the next word: "HTTP|Server"
+	return unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(chars) && unicode.IsLower(chars[i+1])
+}
+
+// skippedBeforeWord reports whether a word motion passes over r before it
+// takes a word. In subword mode underscores are skipped like whitespace.
+func skippedBeforeWord(r rune, subword bool) bool {
+	return unicode.IsSpace(r) || (subword && r == '_')
+}
+
+// nextWordEnd returns where a word motion to the right from pos stops: past
+// any whitespace and then past the word that follows it.
+func nextWordEnd(chars []rune, pos int, subword bool) int {
+	for pos < len(chars) && skippedBeforeWord(chars[pos], subword) {
+		pos++
+	}
+	if pos < len(chars) {
+		pos++
+		for pos < len(chars) && !unicode.IsSpace(chars[pos]) && !wordBoundary(chars, pos, subword) {
+			pos++
+		}
+	}
+	return pos
+}
+
+// previousWordStart returns where a word motion to the left from pos stops:
+// before any whitespace and then at the start of the word before it.
+func previousWordStart(chars []rune, pos int, subword bool) int {
+	for pos > 0 && skippedBeforeWord(chars[pos-1], subword) {
+		pos--
+	}
+	if pos > 0 {
+		pos--
+		for pos > 0 && !unicode.IsSpace(chars[pos-1]) && !wordBoundary(chars, pos, subword) {
+			pos--
+		}
+	}
+	return pos
+}
@@ -0,0 +1,63 @@
+package keyparsing
+
+import (
+	"reflect"
+	"testing"
+)
+
+// wordStops returns every position a motion visits from one end of text to
+// the other.
+func wordStops(text string, subword, right bool) []int {
+	chars := []rune(text)
+	pos := 0
+	if !right {
+		pos = len(chars)
+	}
+
+	var stops []int
+	for {
+		var next int
+		if right {
+			next = nextWordEnd(chars, pos, subword)
+		} else {
+			next = previousWordStart(chars, pos, subword)
+		}
+		if next == pos {
+			return stops
+		}
+		pos = next
+		stops = append(stops, pos)
+	}
+}
+
+func TestWordMotion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		text    string
+		subword bool
+		right   []int
+		left    []int
+	}{
+		{"words", "git commit -m", false, []int{3, 10, 12, 13}, []int{12, 11, 4, 0}},
+		{"camel case as one word", "getFooBar", false, []int{9}, []int{0}},
+		{"snake case as one word", "get_foo_bar", false, []int{11}, []int{0}},
+		{"camel case", "getFooBar", true, []int{3, 6, 9}, []int{6, 3, 0}},
+		{"snake case", "get_foo_bar", true, []int{3, 7, 11}, []int{8, 4, 0}},
+		{"acronym", "parseHTTPServer", true, []int{5, 9, 15}, []int{9, 5, 0}},
+		{"digits", "utf8Decode", true, []int{4, 10}, []int{4, 0}},
+		{"dunder", "__init__", true, []int{6, 8}, []int{2, 0}},
+		{"mixed", "x.fooBar baz_qux", true, []int{1, 2, 5, 8, 12, 16}, []int{13, 9, 5, 2, 1, 0}},
+		{"non-ASCII", "größeÄnderung", true, []int{5, 13}, []int{5, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := wordStops(tc.text, tc.subword, true); !reflect.DeepEqual(got, tc.right) {
+				t.Errorf("Expected right stops %v, got: %v", tc.right, got)
+			}
+			if got := wordStops(tc.text, tc.subword, false); !reflect.DeepEqual(got, tc.left) {
+				t.Errorf("Expected left stops %v, got: %v", tc.left, got)
+			}
+		})
+	}
+}
@@ -104,6 +104,12 @@ impl Buffer {
         self.document().display_cursor_position()
     }
 
+    /// Get the display column of the cursor within the current line,
+    /// accounting for Unicode character widths.
+    pub fn display_column_at_cursor(&mut self) -> usize {
+        self.document().display_column()
+    }
+
     /// Set the text content of the current working line.
     ///
     /// This will invalidate the cached document and reset the cursor position
@@ -321,6 +327,306 @@ impl Buffer {
         }
     }
 
+    /// Convert the text between two rune indices to uppercase.
+    ///
+    /// Uses full Unicode case mapping, so the converted text may contain more
+    /// runes than the original (e.g. German 'ß' becomes "SS"). The range is
+    /// clamped to the text and may be given in either order. The cursor keeps
+    /// its logical position relative to the converted text.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("die straße".to_string());
+    /// buffer.set_cursor_position(10);
+    ///
+    /// buffer.uppercase_range(4, 10);
+    /// assert_eq!(buffer.text(), "die STRASSE");
+    /// assert_eq!(buffer.cursor_position(), 11);
+    /// ```
+    pub fn uppercase_range(&mut self, start: usize, end: usize) {
+        self.transform_range(start, end, str::to_uppercase);
+    }
+
+    /// Convert the text between two rune indices to lowercase.
+    ///
+    /// Uses full Unicode case mapping. See [`Buffer::uppercase_range`] for how
+    /// the range and cursor are handled.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("HELLO ÄÖÜ".to_string());
+    ///
+    /// buffer.lowercase_range(0, 9);
+    /// assert_eq!(buffer.text(), "hello äöü");
+    /// ```
+    pub fn lowercase_range(&mut self, start: usize, end: usize) {
+        self.transform_range(start, end, str::to_lowercase);
+    }
+
+    /// Convert the whole text to uppercase.
+    ///
+    /// The cursor keeps its logical position, as with
+    /// [`uppercase_range`](Self::uppercase_range).
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("straße".to_string());
+    /// buffer.set_cursor_position(6);
+    ///
+    /// buffer.uppercase_text();
+    /// assert_eq!(buffer.text(), "STRASSE");
+    /// assert_eq!(buffer.cursor_position(), 7);
+    /// ```
+    pub fn uppercase_text(&mut self) {
+        self.transform_range(0, usize::MAX, str::to_uppercase);
+    }
+
+    /// Convert the whole text to lowercase.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("HELLO ÄÖÜ".to_string());
+    ///
+    /// buffer.lowercase_text();
+    /// assert_eq!(buffer.text(), "hello äöü");
+    /// ```
+    pub fn lowercase_text(&mut self) {
+        self.transform_range(0, usize::MAX, str::to_lowercase);
+    }
+
+    /// Uppercase from the cursor to the end of the next word (Alt+U) and move
+    /// the cursor past it.
+    ///
+    /// Non-word characters before the word are skipped; words are defined as
+    /// in [`delete_word_after_cursor`](Self::delete_word_after_cursor). Case
+    /// mapping is full Unicode and locale-independent, so 'ß' becomes "SS" and
+    /// the Turkish dotless 'ı' becomes 'I'.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("die straße".to_string());
+    /// buffer.set_cursor_position(3);
+    ///
+    /// buffer.uppercase_word();
+    /// assert_eq!(buffer.text(), "die STRASSE");
+    /// assert_eq!(buffer.cursor_position(), 11);
+    /// ```
+    pub fn uppercase_word(&mut self) {
+        self.transform_word(str::to_uppercase);
+    }
+
+    /// Lowercase from the cursor to the end of the next word (Alt+L) and move
+    /// the cursor past it.
+    ///
+    /// See [`uppercase_word`](Self::uppercase_word) for how the word is found.
+    /// The Turkish dotted 'İ' lowercases to 'i' followed by U+0307 COMBINING
+    /// DOT ABOVE, so the word may grow.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("HELLO WORLD".to_string());
+    ///
+    /// buffer.lowercase_word();
+    /// assert_eq!(buffer.text(), "hello WORLD");
+    /// assert_eq!(buffer.cursor_position(), 5);
+    /// ```
+    pub fn lowercase_word(&mut self) {
+        self.transform_word(str::to_lowercase);
+    }
+
+    /// Capitalize the next word (Alt+C): uppercase its first character,
+    /// lowercase the rest, and move the cursor past it.
+    ///
+    /// See [`uppercase_word`](Self::uppercase_word) for how the word is found.
+    /// A first character whose uppercase form has several characters keeps
+    /// only the first of them uppercase, so "ßtraße" becomes "Sstraße".
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("hello wORLD".to_string());
+    /// buffer.set_cursor_position(5);
+    ///
+    /// buffer.capitalize_word();
+    /// assert_eq!(buffer.text(), "hello World");
+    /// assert_eq!(buffer.cursor_position(), 11);
+    /// ```
+    pub fn capitalize_word(&mut self) {
+        self.transform_word(capitalize);
+    }
+
+    /// Apply `transform` from the cursor to the end of the next word and
+    /// place the cursor after the transformed word.
+    fn transform_word(&mut self, transform: fn(&str) -> String) {
+        let chars: Vec<char> = self.document().text_after_cursor().chars().collect();
+        let mut len = 0;
+        while len < chars.len() && !is_word_char(chars[len]) {
+            len += 1;
+        }
+        while len < chars.len() && is_word_char(chars[len]) {
+            len += 1;
+        }
+        if len == 0 {
+            return;
+        }
+
+        let start = self.cursor_position;
+        let target: String = chars[..len].iter().collect();
+        let transformed_len = unicode::rune_count(&transform(&target));
+        self.transform_range(start, start + len, transform);
+        self.set_cursor_position(start + transformed_len);
+    }
+
+    /// Replace the runes in `[start, end)` with `transform` applied to them,
+    /// shifting the cursor by the change in rune count.
+    fn transform_range(&mut self, start: usize, end: usize, transform: fn(&str) -> String) {
+        let current_text = self.text().to_string();
+        let text_rune_count = unicode::rune_count(&current_text);
+
+        let start_pos = start.min(end).min(text_rune_count);
+        let end_pos = start.max(end).min(text_rune_count);
+        if start_pos == end_pos {
+            return;
+        }
+
+        let before = unicode::rune_slice(&current_text, 0, start_pos);
+        let target = unicode::rune_slice(&current_text, start_pos, end_pos);
+        let after = unicode::rune_slice(&current_text, end_pos, text_rune_count);
+        let transformed = transform(target);
+
+        let cursor_pos = self.cursor_position;
+        if cursor_pos >= end_pos {
+            self.cursor_position =
+                cursor_pos + unicode::rune_count(&transformed) - unicode::rune_count(target);
+        } else if cursor_pos > start_pos {
+            let inside = unicode::rune_slice(&current_text, start_pos, cursor_pos);
+            self.cursor_position = start_pos + unicode::rune_count(&transform(inside));
+        }
+
+        self.working_lines[self.working_index] = format!("{before}{transformed}{after}");
+        self.invalidate_cache();
+    }
+
+    /// Reflow the text so that lines wrap at word boundaries within `width`
+    /// display columns.
+    ///
+    /// Existing newlines are kept as paragraph breaks and each line's leading
+    /// indentation is preserved on its first wrapped line. Runs of whitespace
+    /// between words are collapsed into a single space or a line break. A word
+    /// wider than `width` is placed on a line of its own rather than split. The
+    /// cursor stays next to the same non-whitespace character. A `width` of zero
+    /// leaves the buffer unchanged.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("the quick brown fox".to_string());
+    /// buffer.set_cursor_position(11); // after "the quick b"
+    ///
+    /// buffer.word_wrap(10);
+    /// assert_eq!(buffer.text(), "the quick\nbrown fox");
+    /// assert_eq!(buffer.cursor_position(), 11);
+    /// ```
+    pub fn word_wrap(&mut self, width: usize) {
+        if width == 0 {
+            return;
+        }
+
+        let current_text = self.text().to_string();
+        let mut wrapped = String::with_capacity(current_text.len());
+
+        for (index, line) in current_text.split('\n').enumerate() {
+            if index > 0 {
+                wrapped.push('\n');
+            }
+
+            let content = line.trim_start();
+            let indent = &line[..line.len() - content.len()];
+            wrapped.push_str(indent);
+            let mut line_width = unicode::display_width(indent);
+            let mut at_line_start = true;
+
+            for word in content.split_whitespace() {
+                let word_width = unicode::display_width(word);
+                if !at_line_start && line_width + 1 + word_width > width {
+                    wrapped.push('\n');
+                    line_width = 0;
+                    at_line_start = true;
+                }
+                if !at_line_start {
+                    wrapped.push(' ');
+                    line_width += 1;
+                }
+                wrapped.push_str(word);
+                line_width += word_width;
+                at_line_start = false;
+            }
+        }
+
+        // Keep the cursor next to the same non-whitespace character: after the
+        // one before it, or before the one it was on
+        let chars: Vec<char> = current_text.chars().collect();
+        let cursor_pos = self.cursor_position.min(chars.len());
+        let mut anchor = chars[..cursor_pos]
+            .iter()
+            .filter(|c| !c.is_whitespace())
+            .count();
+        let before_anchor = chars.get(cursor_pos).is_some_and(|c| !c.is_whitespace());
+        if before_anchor {
+            anchor += 1;
+        }
+
+        let mut new_cursor = 0;
+        let mut seen = 0;
+        for (i, c) in wrapped.chars().enumerate() {
+            if seen == anchor {
+                break;
+            }
+            if !c.is_whitespace() {
+                seen += 1;
+                new_cursor = if before_anchor && seen == anchor {
+                    i
+                } else {
+                    i + 1
+                };
+            }
+        }
+
+        self.working_lines[self.working_index] = wrapped;
+        self.cursor_position = new_cursor;
+        self.invalidate_cache();
+    }
+
     /// Insert text at the current cursor position.
     ///
     /// # Arguments
@@ -428,6 +734,9 @@ impl Buffer {
 
     /// Delete text before the cursor.
     ///
+    /// Newlines count as characters, so deleting at the start of a line joins
+    /// it to the previous line and leaves the cursor at the join.
+    ///
     /// # Arguments
     ///
     /// * `count` - Number of characters to delete
@@ -482,6 +791,9 @@ impl Buffer {
 
         self.working_lines[self.working_index] = new_text;
         self.cursor_position = delete_start;
+        // The cursor may have moved to another line by joining, so the column
+        // remembered by vertical movement no longer applies
+        self.preferred_column = None;
         self.invalidate_cache();
 
         deleted_text
@@ -530,6 +842,83 @@ impl Buffer {
         Ok(self.delete_before_cursor(count))
     }
 
+    /// Delete the word before the cursor (Ctrl+W) and return the deleted text.
+    ///
+    /// Whitespace directly before the cursor is deleted together with the word
+    /// before it. A word is a run of alphanumeric characters and underscores, or
+    /// a run of other non-whitespace characters, so punctuation such as `/` or
+    /// `.` is removed separately from the words around it.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("cd /usr/local  ".to_string());
+    /// buffer.set_cursor_position(15);
+    ///
+    /// assert_eq!(buffer.delete_word_before_cursor(), "local  ");
+    /// assert_eq!(buffer.delete_word_before_cursor(), "/");
+    /// assert_eq!(buffer.text(), "cd /usr");
+    /// ```
+    pub fn delete_word_before_cursor(&mut self) -> String {
+        let chars: Vec<char> = self.document().text_before_cursor().chars().collect();
+        let mut pos = chars.len();
+
+        while pos > 0 && chars[pos - 1].is_whitespace() {
+            pos -= 1;
+        }
+        if pos > 0 {
+            let word = is_word_char(chars[pos - 1]);
+            while pos > 0 && !chars[pos - 1].is_whitespace() && is_word_char(chars[pos - 1]) == word
+            {
+                pos -= 1;
+            }
+        }
+
+        self.delete_before_cursor(chars.len() - pos)
+    }
+
+    /// Delete the word after the cursor (Alt+D) and return the deleted text.
+    ///
+    /// Whitespace directly after the cursor is deleted together with the word
+    /// that follows it. Words are defined as in
+    /// [`delete_word_before_cursor`](Self::delete_word_before_cursor).
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::buffer::Buffer;
+    ///
+    /// let mut buffer = Buffer::new();
+    /// buffer.set_text("git  commit -m".to_string());
+    /// buffer.set_cursor_position(3);
+    ///
+    /// assert_eq!(buffer.delete_word_after_cursor(), "  commit");
+    /// assert_eq!(buffer.text(), "git -m");
+    /// assert_eq!(buffer.cursor_position(), 3);
+    /// ```
+    pub fn delete_word_after_cursor(&mut self) -> String {
+        let chars: Vec<char> = self.document().text_after_cursor().chars().collect();
+        let mut pos = 0;
+
+        while pos < chars.len() && chars[pos].is_whitespace() {
+            pos += 1;
+        }
+        if pos < chars.len() {
+            let word = is_word_char(chars[pos]);
+            while pos < chars.len()
+                && !chars[pos].is_whitespace()
+                && is_word_char(chars[pos]) == word
+            {
+                pos += 1;
+            }
+        }
+
+        self.delete(pos)
+    }
+
     /// Delete text after the cursor.
     ///
     /// # Arguments
@@ -1007,6 +1396,30 @@ impl Default for Buffer {
     }
 }
 
+/// Uppercase the first word character of `s` and lowercase everything else.
+/// Only the first character of a multi-character uppercase form is kept
+/// uppercase ('ß' becomes "Ss").
+fn capitalize(s: &str) -> String {
+    let mut result = String::with_capacity(s.len());
+    let mut capitalized = false;
+    for c in s.chars() {
+        if !capitalized && is_word_char(c) {
+            let mut upper = c.to_uppercase();
+            result.extend(upper.next());
+            result.extend(upper.flat_map(char::to_lowercase));
+            capitalized = true;
+        } else {
+            result.extend(c.to_lowercase());
+        }
+    }
+    result
+}
+
+/// Whether `c` belongs to a word for word-wise deletion.
+fn is_word_char(c: char) -> bool {
+    c.is_alphanumeric() || c == '_'
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -1385,6 +1798,16 @@ mod tests {
         assert!(display_pos >= 2); // Should be at least the rune position
     }
 
+    #[test]
+    fn test_display_column_at_cursor() {
+        let mut buffer = Buffer::new();
+        buffer.set_text("first\n漢字ab".to_string());
+        buffer.set_cursor_position(9);
+
+        assert_eq!(buffer.document().cursor_position_col(), 3);
+        assert_eq!(buffer.display_column_at_cursor(), 5);
+    }
+
     #[test]
     fn test_cache_invalidation() {
         let mut buffer = Buffer::new();
@@ -1598,6 +2021,156 @@ mod tests {
         assert_eq!(buffer.cursor_position(), 3);
     }
 
+    #[test]
+    fn test_delete_before_cursor_joins_lines() {
+        let mut buffer = Buffer::new();
+        buffer.set_text("one\ntwo\nthree".to_string());
+
+        // At column 0 the preceding newline is removed and the cursor sits at the join
+        buffer.set_cursor_position(4);
+        assert_eq!(buffer.delete_before_cursor(1), "\n");
+        assert_eq!(buffer.text(), "onetwo\nthree");
+        assert_eq!(buffer.cursor_position(), 3);
+        assert_eq!(buffer.document().cursor_position_row(), 0);
+        assert_eq!(buffer.document().cursor_position_col(), 3);
+
+        // Deleting further crosses the boundary in one call
+        buffer.set_cursor_position(8);
+        assert_eq!(buffer.delete_before_cursor(4), "wo\nt");
+        assert_eq!(buffer.text(), "onethree");
+        assert_eq!(buffer.cursor_position(), 4);
+
+        // A join discards the column remembered by vertical movement
+        buffer.set_text("abc\n\nxyz".to_string());
+        buffer.set_cursor_position(2);
+        buffer.cursor_down(1);
+        assert_eq!(buffer.cursor_position(), 4);
+        buffer.delete_before_cursor(1);
+        assert_eq!(buffer.text(), "abc\nxyz");
+        assert_eq!(buffer.cursor_position(), 3);
+        buffer.cursor_down(1);
+        assert_eq!(buffer.cursor_position(), 7);
+    }
+
+    #[test]
+    fn test_delete_word_before_cursor() {
+        let mut buffer = Buffer::new();
+        buffer.set_text("hello   world".to_string());
+        buffer.set_cursor_position(13);
+        assert_eq!(buffer.delete_word_before_cursor(), "world");
+        assert_eq!(buffer.delete_word_before_cursor(), "hello   ");
+        assert_eq!(buffer.text(), "");
+        assert_eq!(buffer.cursor_position(), 0);
+
+        // Nothing to delete at the start of the buffer
+        buffer.set_text("hello".to_string());
+        buffer.set_cursor_position(0);
+        assert_eq!(buffer.delete_word_before_cursor(), "");
+        assert_eq!(buffer.text(), "hello");
+
+        // Only text before the cursor is affected
+        buffer.set_text("foo.bar baz".to_string());
+        buffer.set_cursor_position(7);
+        assert_eq!(buffer.delete_word_before_cursor(), "bar");
+        assert_eq!(buffer.delete_word_before_cursor(), ".");
+        assert_eq!(buffer.text(), "foo baz");
+        assert_eq!(buffer.cursor_position(), 3);
+
+        // Rune indices with wide characters
+        buffer.set_text("こんにちは 世界".to_string());
+        buffer.set_cursor_position(8);
+        assert_eq!(buffer.delete_word_before_cursor(), "世界");
+        assert_eq!(buffer.text(), "こんにちは ");
+        assert_eq!(buffer.cursor_position(), 6);
+    }
+
+    #[test]
+    fn test_delete_word_after_cursor() {
+        let mut buffer = Buffer::new();
+        buffer.set_text("hello   world  ".to_string());
+        buffer.set_cursor_position(5);
+        assert_eq!(buffer.delete_word_after_cursor(), "   world");
+        assert_eq!(buffer.text(), "hello  ");
+        assert_eq!(buffer.cursor_position(), 5);
+
+        // Trailing whitespace only, then end of buffer
+        assert_eq!(buffer.delete_word_after_cursor(), "  ");
+        assert_eq!(buffer.delete_word_after_cursor(), "");
+        assert_eq!(buffer.text(), "hello");
+
+        // Punctuation is a separate word
+        buffer.set_text("foo.bar".to_string());
+        buffer.set_cursor_position(0);
+        assert_eq!(buffer.delete_word_after_cursor(), "foo");
+        assert_eq!(buffer.delete_word_after_cursor(), ".");
+        assert_eq!(buffer.text(), "bar");
+
+        // Unicode word boundaries
+        buffer.set_text("日本語 テキスト".to_string());
+        buffer.set_cursor_position(1);
+        assert_eq!(buffer.delete_word_after_cursor(), "本語");
+        assert_eq!(buffer.text(), "日 テキスト");
+        assert_eq!(buffer.cursor_position(), 1);
+    }
+
+    #[test]
+    fn test_word_wrap() {
+        let mut buffer = Buffer::new();
+        buffer.set_text(
+            "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod".to_string(),
+        );
+        buffer.set_cursor_position(0);
+        buffer.word_wrap(20);
+
+        for line in buffer.text().split('\n') {
+            assert!(
+                unicode::display_width(line) <= 20,
+                "line too wide: {line:?}"
+            );
+            assert!(!line.starts_with(' ') && !line.ends_with(' '));
+        }
+        assert_eq!(
+            buffer
+                .text()
+                .split_whitespace()
+                .collect::<Vec<_>>()
+                .join(" "),
+            "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod"
+        );
+
+        // Paragraph breaks and indentation are kept
+        buffer.set_text("  aaa bbb ccc\n\nddd".to_string());
+        buffer.word_wrap(8);
+        assert_eq!(buffer.text(), "  aaa\nbbb ccc\n\nddd");
+
+        // Wide characters count as two columns; long words are not split
+        buffer.set_text("日本語 テキスト abcdefghijkl".to_string());
+        buffer.word_wrap(10);
+        assert_eq!(buffer.text(), "日本語\nテキスト\nabcdefghijkl");
+    }
+
+    #[test]
+    fn test_word_wrap_cursor() {
+        let mut buffer = Buffer::new();
+        buffer.set_text("one two   three".to_string());
+
+        // Cursor on "t" of "three" stays before it
+        buffer.set_cursor_position(10);
+        buffer.word_wrap(7);
+        assert_eq!(buffer.text(), "one two\nthree");
+        assert_eq!(buffer.cursor_position(), 8);
+
+        // Cursor at the end stays at the end
+        buffer.set_text("one two   three".to_string());
+        buffer.set_cursor_position(15);
+        buffer.word_wrap(7);
+        assert_eq!(buffer.cursor_position(), 13);
+
+        // Zero width is a no-op
+        buffer.word_wrap(0);
+        assert_eq!(buffer.text(), "one two\nthree");
+    }
+
     #[test]
     fn test_delete_after_cursor_basic() {
         let mut buffer = Buffer::new();
@@ -2584,4 +3157,87 @@ mod tests {
         assert_eq!(buffer.text(), "ba");
         assert_eq!(buffer.cursor_position(), 2);
     }
+
+    #[test]
+    fn test_case_transform_range() {
+        let mut buffer = Buffer::new();
+
+        // Special casing grows the text and shifts a trailing cursor
+        buffer.set_text("maße und gewicht".to_string());
+        buffer.set_cursor_position(16);
+        buffer.uppercase_range(0, 4);
+        assert_eq!(buffer.text(), "MASSE und gewicht");
+        assert_eq!(buffer.cursor_position(), 17);
+
+        // Cursor before the range is unchanged, reversed range is accepted
+        buffer.set_cursor_position(0);
+        buffer.uppercase_range(9, 6);
+        assert_eq!(buffer.text(), "MASSE UND gewicht");
+        assert_eq!(buffer.cursor_position(), 0);
+
+        // Cursor inside the range stays on the same logical character
+        buffer.set_text("éçà straße".to_string());
+        buffer.set_cursor_position(9);
+        buffer.uppercase_range(0, 10);
+        assert_eq!(buffer.text(), "ÉÇÀ STRASSE");
+        assert_eq!(buffer.cursor_position(), 10);
+
+        // Out of bounds ranges are clamped
+        buffer.lowercase_range(4, 100);
+        assert_eq!(buffer.text(), "ÉÇÀ strasse");
+
+        // Empty range is a no-op
+        buffer.lowercase_range(2, 2);
+        assert_eq!(buffer.text(), "ÉÇÀ strasse");
+    }
+
+    #[test]
+    fn test_case_transform_word() {
+        let mut buffer = Buffer::new();
+
+        // Leading non-word characters are skipped, the cursor lands after the word
+        buffer.set_text("git  commit -m".to_string());
+        buffer.set_cursor_position(3);
+        buffer.uppercase_word();
+        assert_eq!(buffer.text(), "git  COMMIT -m");
+        assert_eq!(buffer.cursor_position(), 11);
+
+        // Starting mid-word only transforms the rest of the word
+        buffer.set_text("HELLO".to_string());
+        buffer.set_cursor_position(2);
+        buffer.lowercase_word();
+        assert_eq!(buffer.text(), "HEllo");
+        assert_eq!(buffer.cursor_position(), 5);
+
+        // Nothing after the cursor is a no-op
+        buffer.uppercase_word();
+        assert_eq!(buffer.text(), "HEllo");
+        assert_eq!(buffer.cursor_position(), 5);
+
+        // German sharp s grows when uppercased and titlecases to "Ss"
+        buffer.set_text("maß ßtraße".to_string());
+        buffer.set_cursor_position(0);
+        buffer.uppercase_word();
+        assert_eq!(buffer.text(), "MASS ßtraße");
+        assert_eq!(buffer.cursor_position(), 4);
+        buffer.capitalize_word();
+        assert_eq!(buffer.text(), "MASS Sstraße");
+        assert_eq!(buffer.cursor_position(), 12);
+
+        // Turkish i: mapping is locale-independent
+        buffer.set_text("ılık İSTANBUL".to_string());
+        buffer.set_cursor_position(0);
+        buffer.uppercase_word();
+        assert_eq!(buffer.text(), "ILIK İSTANBUL");
+        buffer.lowercase_word();
+        assert_eq!(buffer.text(), "ILIK i\u{307}stanbul");
+        assert_eq!(buffer.cursor_position(), 14);
+
+        // Capitalize lowercases the rest and treats underscores as word characters
+        buffer.set_text("(mY_VAR)".to_string());
+        buffer.set_cursor_position(0);
+        buffer.capitalize_word();
+        assert_eq!(buffer.text(), "(My_var)");
+        assert_eq!(buffer.cursor_position(), 7);
+    }
 }
@@ -527,6 +527,57 @@ impl Document {
         self.find_word_boundary_after(false, None)
     }
 
+    /// Get the rune range of the word containing the cursor.
+    ///
+    /// A word is defined as a sequence of non-whitespace characters. A cursor
+    /// directly before or after a word counts as inside it. When the cursor is
+    /// surrounded by whitespace, an empty range at the cursor is returned.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::document::Document;
+    ///
+    /// let doc = Document::with_text("hello world".to_string(), 8);
+    /// assert_eq!(doc.current_word_range(), (6, 11));
+    ///
+    /// let doc2 = Document::with_text("hello  world".to_string(), 6);
+    /// assert_eq!(doc2.current_word_range(), (6, 6));
+    /// ```
+    pub fn current_word_range(&self) -> (usize, usize) {
+        let chars: Vec<char> = self.text.chars().collect();
+        let mut start = self.cursor_position.min(chars.len());
+        let mut end = start;
+
+        while start > 0 && !chars[start - 1].is_whitespace() {
+            start -= 1;
+        }
+        while end < chars.len() && !chars[end].is_whitespace() {
+            end += 1;
+        }
+
+        (start, end)
+    }
+
+    /// Get the whole word containing the cursor.
+    ///
+    /// Unlike [`get_word_before_cursor`](Self::get_word_before_cursor) and
+    /// [`get_word_after_cursor`](Self::get_word_after_cursor), the word is not
+    /// split at the cursor. See [`current_word_range`](Self::current_word_range).
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::document::Document;
+    ///
+    /// let doc = Document::with_text("hello world".to_string(), 2);
+    /// assert_eq!(doc.current_word(), "hello");
+    /// ```
+    pub fn current_word(&self) -> &str {
+        let (start, end) = self.current_word_range();
+        unicode::rune_slice(&self.text, start, end)
+    }
+
     /// Find the start position of the previous word, including whitespace.
     ///
     /// This variant includes whitespace when determining word boundaries.
@@ -1099,6 +1150,24 @@ impl Document {
         self.cursor_position - line_start
     }
 
+    /// Get the display column of the cursor within the current line.
+    ///
+    /// Unlike [`cursor_position_col`](Self::cursor_position_col), wide characters
+    /// (like CJK) count as two columns.
+    ///
+    /// # Examples
+    ///
+    /// ```
+    /// use replkit_core::document::Document;
+    ///
+    /// let doc = Document::with_text("ab\n日本x".to_string(), 6);
+    /// assert_eq!(doc.cursor_position_col(), 3);
+    /// assert_eq!(doc.display_column(), 5);
+    /// ```
+    pub fn display_column(&self) -> usize {
+        unicode::display_width(self.current_line_before_cursor())
+    }
+
     /// Translate a linear rune index to (row, column) coordinates.
     ///
     /// # Arguments
@@ -1504,6 +1573,43 @@ mod tests {
         assert_eq!(doc_cjk.display_cursor_position(), 4); // Each char is 2 columns
     }
 
+    #[test]
+    fn test_current_word() {
+        let doc = Document::with_text("hello".to_string(), 2);
+        assert_eq!(doc.current_word(), "hello");
+        assert_eq!(doc.current_word_range(), (0, 5));
+
+        // Cursor at either edge of a word
+        let doc = Document::with_text("foo bar".to_string(), 3);
+        assert_eq!(doc.current_word(), "foo");
+        let doc = Document::with_text("foo bar".to_string(), 4);
+        assert_eq!(doc.current_word(), "bar");
+
+        // Cursor between whitespace
+        let doc = Document::with_text("foo   bar".to_string(), 4);
+        assert_eq!(doc.current_word(), "");
+        assert_eq!(doc.current_word_range(), (4, 4));
+
+        // Multi-line and Unicode text
+        let doc = Document::with_text("one\nこんにちは two".to_string(), 6);
+        assert_eq!(doc.current_word(), "こんにちは");
+        assert_eq!(doc.current_word_range(), (4, 9));
+
+        let empty = Document::new();
+        assert_eq!(empty.current_word(), "");
+    }
+
+    #[test]
+    fn test_display_column() {
+        let doc = Document::with_text("hello\nworld".to_string(), 8);
+        assert_eq!(doc.display_column(), 2);
+
+        // Only the current line counts, and wide characters take two columns
+        let doc_cjk = Document::with_text("日本\n語x".to_string(), 5);
+        assert_eq!(doc_cjk.cursor_position_col(), 2);
+        assert_eq!(doc_cjk.display_column(), 3);
+    }
+
     #[test]
     fn test_get_char_relative_to_cursor() {
         let doc = Document::with_text("hello".to_string(), 2);
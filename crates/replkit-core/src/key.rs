@@ -136,6 +136,9 @@ pub struct KeyEvent {
     /// Optional text content associated with this key event
     /// (e.g., for printable characters or bracketed paste content)
     pub text: Option<String>,
+    /// True for an interim chunk of a bracketed paste whose end sequence has
+    /// not arrived yet (see `KeyParser::set_incremental_paste`)
+    pub incomplete: bool,
 }
 
 impl KeyEvent {
@@ -145,6 +148,7 @@ impl KeyEvent {
             key,
             raw_bytes,
             text,
+            incomplete: false,
         }
     }
 
@@ -175,6 +179,7 @@ impl Default for KeyEvent {
             key: Key::NotDefined,
             raw_bytes: Vec::new(),
             text: None,
+            incomplete: false,
         }
     }
 }
@@ -35,6 +35,8 @@ pub struct KeyParser {
     sequence_matcher: SequenceMatcher,
     /// Buffer for bracketed paste content
     paste_buffer: Vec<u8>,
+    /// Whether paste content is delivered in chunks at the end of each feed
+    incremental_paste: bool,
 }
 
 impl KeyParser {
@@ -45,9 +47,23 @@ impl KeyParser {
             buffer: Vec::new(),
             sequence_matcher: SequenceMatcher::new(),
             paste_buffer: Vec::new(),
+            incremental_paste: false,
         }
     }
 
+    /// Enable or disable incremental delivery of bracketed paste content
+    ///
+    /// When enabled, each `feed` call made while a paste is in progress ends by
+    /// emitting the paste content received so far as a `BracketedPaste` event with
+    /// `incomplete` set, so large pastes can be inserted progressively instead of
+    /// being buffered until the end sequence. The final event for the paste carries
+    /// only the remaining content and has `incomplete` unset. Concatenating the text
+    /// of all chunks yields the full paste. Chunks are split on UTF-8 character
+    /// boundaries. Disabled by default, in which case a paste produces one event.
+    pub fn set_incremental_paste(&mut self, enabled: bool) {
+        self.incremental_paste = enabled;
+    }
+
     /// Feed raw bytes to the parser and return any complete key events
     ///
     /// This method processes the input bytes according to the current parser state
@@ -85,6 +101,12 @@ impl KeyParser {
             }
         }
 
+        if self.incremental_paste && self.state == ParserState::BracketedPaste {
+            if let Some(event) = self.take_paste_chunk() {
+                events.push(event);
+            }
+        }
+
         events
     }
 
@@ -223,11 +245,9 @@ impl KeyParser {
         // Check if we have a complete CSI sequence
         match self.sequence_matcher.match_sequence(&self.buffer) {
             MatchResult::Exact(key) => {
-                if key == Key::Ignore {
-                    // Ignore this sequence
-                } else {
-                    events.push(KeyEvent::simple(key, self.buffer.clone()));
-                }
+                // Ignore sequences are still emitted (without text) so callers can
+                // tell them apart from unknown input and log the raw bytes
+                events.push(KeyEvent::simple(key, self.buffer.clone()));
                 self.reset_to_normal();
             }
             MatchResult::Prefix => {
@@ -316,21 +336,7 @@ impl KeyParser {
             }
 
             // Emit bracketed paste event
-            if let Ok(text) = String::from_utf8(self.paste_buffer.clone()) {
-                events.push(KeyEvent::with_text(
-                    Key::BracketedPaste,
-                    self.paste_buffer.clone(),
-                    text,
-                ));
-            } else {
-                // Invalid UTF-8, emit as raw bytes
-                events.push(KeyEvent::simple(
-                    Key::BracketedPaste,
-                    self.paste_buffer.clone(),
-                ));
-            }
-
-            self.paste_buffer.clear();
+            events.push(Self::paste_event(std::mem::take(&mut self.paste_buffer)));
             self.reset_to_normal();
         } else if self.buffer.len() >= 6 {
             // If buffer is getting long and we haven't found the end sequence,
@@ -343,6 +349,38 @@ impl KeyParser {
         }
     }
 
+    /// Take the paste content accumulated so far as an incomplete chunk
+    ///
+    /// A trailing partial UTF-8 character is kept for the next chunk. Bytes still
+    /// held in `buffer` (a possible partial end sequence) are not included.
+    fn take_paste_chunk(&mut self) -> Option<KeyEvent> {
+        let split = match std::str::from_utf8(&self.paste_buffer) {
+            Ok(_) => self.paste_buffer.len(),
+            // Incomplete character at the end: hold it back
+            Err(e) if e.error_len().is_none() => e.valid_up_to(),
+            // Invalid UTF-8: deliver everything as raw bytes
+            Err(_) => self.paste_buffer.len(),
+        };
+        if split == 0 {
+            return None;
+        }
+
+        let rest = self.paste_buffer.split_off(split);
+        let chunk = std::mem::replace(&mut self.paste_buffer, rest);
+        let mut event = Self::paste_event(chunk);
+        event.incomplete = true;
+        Some(event)
+    }
+
+    /// Build a BracketedPaste event, with text when the content is valid UTF-8
+    fn paste_event(content: Vec<u8>) -> KeyEvent {
+        match String::from_utf8(content) {
+            Ok(text) => KeyEvent::with_text(Key::BracketedPaste, text.as_bytes().to_vec(), text),
+            // Invalid UTF-8, emit as raw bytes
+            Err(e) => KeyEvent::simple(Key::BracketedPaste, e.into_bytes()),
+        }
+    }
+
     /// Check if a byte is a CSI parameter byte (digits, semicolon, etc.)
     fn is_csi_parameter_byte(&self, byte: u8) -> bool {
         matches!(byte, b'0'..=b'9' | b';' | b':' | b'<' | b'=' | b'>' | b'?')
@@ -727,9 +765,12 @@ mod tests {
     fn test_ignore_sequences() {
         let mut parser = KeyParser::new();
 
-        // Test sequence that should be ignored
+        // Ignored sequences are reported with their raw bytes and no text
         let events = parser.feed(&[0x1b, 0x5b, 0x45]);
-        assert_eq!(events.len(), 0); // Should be ignored
+        assert_eq!(events.len(), 1);
+        assert_eq!(events[0].key, Key::Ignore);
+        assert_eq!(events[0].raw_bytes, vec![0x1b, 0x5b, 0x45]);
+        assert!(events[0].text.is_none());
         assert_eq!(parser.state, ParserState::Normal);
     }
 
@@ -851,6 +892,45 @@ mod tests {
         assert_eq!(parser.state, ParserState::Normal);
     }
 
+    #[test]
+    fn test_incremental_bracketed_paste() {
+        let mut parser = KeyParser::new();
+        parser.set_incremental_paste(true);
+
+        let payload = "first line\nsecond line with ünïcödé\nthird";
+        let input = format!("\x1b[200~{payload}\x1b[201~");
+        let bytes = input.as_bytes();
+
+        // Split into three reads, one of them inside a multi-byte character
+        let split_char = input.find('ü').unwrap() + 1;
+        let reads = [&bytes[..12], &bytes[12..split_char], &bytes[split_char..]];
+
+        let mut events = Vec::new();
+        for read in reads {
+            events.extend(parser.feed(read));
+        }
+
+        assert!(events.len() >= 2);
+        assert!(events.iter().all(|e| e.key == Key::BracketedPaste));
+        let (last, chunks) = events.split_last().unwrap();
+        assert!(chunks.iter().all(|e| e.incomplete));
+        assert!(!last.incomplete);
+
+        let joined: String = events.iter().map(|e| e.text_or_empty()).collect();
+        assert_eq!(joined, payload);
+        assert_eq!(parser.state, ParserState::Normal);
+
+        // Disabled by default: a single complete event
+        let mut parser = KeyParser::new();
+        let mut events = Vec::new();
+        for read in reads {
+            events.extend(parser.feed(read));
+        }
+        assert_eq!(events.len(), 1);
+        assert!(!events[0].incomplete);
+        assert_eq!(events[0].text_or_empty(), payload);
+    }
+
     #[test]
     fn test_multiple_sequences_in_one_feed() {
         let mut parser = KeyParser::new();
@@ -15,6 +15,8 @@ mod wasm_impl {
         pub key: u32,
         pub raw_bytes: Vec<u8>,
         pub text: Option<String>,
+        #[serde(default)]
+        pub incomplete: bool,
     }
 
     impl From<KeyEvent> for WasmKeyEvent {
@@ -23,6 +25,7 @@ mod wasm_impl {
                 key: key_to_u32(event.key),
                 raw_bytes: event.raw_bytes,
                 text: event.text,
+                incomplete: event.incomplete,
             }
         }
     }
@@ -33,6 +36,7 @@ mod wasm_impl {
                 key: u32_to_key(wasm_event.key),
                 raw_bytes: wasm_event.raw_bytes,
                 text: wasm_event.text,
+                incomplete: wasm_event.incomplete,
             }
         }
     }
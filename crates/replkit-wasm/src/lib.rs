@@ -174,6 +174,19 @@ pub extern "C" fn reset(parser_id: u32) {
     }
 }
 
+#[no_mangle]
+pub extern "C" fn set_incremental_paste(parser_id: u32, enabled: u32) {
+    init_parsers();
+
+    unsafe {
+        if let Some(ref mut parsers) = PARSERS {
+            if let Some(parser) = parsers.get_mut(&parser_id) {
+                parser.set_incremental_paste(enabled != 0);
+            }
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn destroy_parser(parser_id: u32) {
     init_parsers();
@@ -187,7 +200,9 @@ pub extern "C" fn destroy_parser(parser_id: u32) {
 
 #[no_mangle]
 pub extern "C" fn malloc(size: usize) -> *mut c_void {
-    unsafe { __wbindgen_malloc(size) as *mut c_void }
+    // Tracked so that the exported free, which only knows the pointer, can
+    // hand the block back with its size
+    allocate_tracked(size) as *mut c_void
 }
 
 #[no_mangle]
@@ -331,6 +346,42 @@ pub extern "C" fn buffer_delete_before_cursor(buffer_id: u32, count: u32) -> u64
     }
 }
 
+#[no_mangle]
+pub extern "C" fn buffer_delete_word_before_cursor(buffer_id: u32) -> u64 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                let deleted = buffer.delete_word_before_cursor();
+                serialize_string(&deleted)
+            } else {
+                0 // Error: buffer not found
+            }
+        } else {
+            0 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_delete_word_after_cursor(buffer_id: u32) -> u64 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                let deleted = buffer.delete_word_after_cursor();
+                serialize_string(&deleted)
+            } else {
+                0 // Error: buffer not found
+            }
+        } else {
+            0 // Error: buffers not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn buffer_delete(buffer_id: u32, count: u32) -> u64 {
     init_buffers();
@@ -539,6 +590,251 @@ pub extern "C" fn buffer_swap_characters_before_cursor(buffer_id: u32) -> u32 {
     }
 }
 
+#[no_mangle]
+pub extern "C" fn buffer_uppercase_range(buffer_id: u32, start: u32, end: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.uppercase_range(start as usize, end as usize);
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_lowercase_range(buffer_id: u32, start: u32, end: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.lowercase_range(start as usize, end as usize);
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_uppercase_text(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.uppercase_text();
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_lowercase_text(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.lowercase_text();
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_uppercase_word(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.uppercase_word();
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_lowercase_word(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.lowercase_word();
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_capitalize_word(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.capitalize_word();
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn buffer_word_wrap(buffer_id: u32, width: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                buffer.word_wrap(width as usize);
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
+/// A single operation in a `buffer_apply_edits` batch
+#[derive(serde::Deserialize)]
+#[serde(tag = "op", rename_all = "snake_case")]
+enum BufferEdit {
+    Insert {
+        #[serde(default)]
+        text: String,
+        #[serde(default)]
+        overwrite: bool,
+    },
+    DeleteBeforeCursor {
+        #[serde(default)]
+        count: usize,
+    },
+    Delete {
+        #[serde(default)]
+        count: usize,
+    },
+    CursorLeft {
+        #[serde(default)]
+        count: usize,
+    },
+    CursorRight {
+        #[serde(default)]
+        count: usize,
+    },
+    CursorUp {
+        #[serde(default)]
+        count: usize,
+    },
+    CursorDown {
+        #[serde(default)]
+        count: usize,
+    },
+    SetCursorPosition {
+        #[serde(default)]
+        position: usize,
+    },
+}
+
+/// Apply a JSON array of edits to a buffer in a single call
+///
+/// The whole batch is parsed before any edit is applied, so malformed input
+/// leaves the buffer untouched.
+///
+/// # Safety
+/// The caller must ensure that `edits_ptr` points to a valid UTF-8 JSON memory region of at least `edits_len` bytes.
+#[no_mangle]
+pub unsafe extern "C" fn buffer_apply_edits(
+    buffer_id: u32,
+    edits_ptr: *const u8,
+    edits_len: u32,
+) -> u32 {
+    init_buffers();
+
+    let edits_json = unsafe {
+        let slice = slice::from_raw_parts(edits_ptr, edits_len as usize);
+        match str::from_utf8(slice) {
+            Ok(s) => s,
+            Err(_) => return 1, // Error: invalid UTF-8
+        }
+    };
+
+    let edits = match serde_json::from_str::<Vec<BufferEdit>>(edits_json) {
+        Ok(edits) => edits,
+        Err(_) => return 1, // Error: invalid edits
+    };
+
+    unsafe {
+        if let Some(ref mut buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get_mut(&buffer_id) {
+                for edit in edits {
+                    match edit {
+                        BufferEdit::Insert { text, overwrite } => {
+                            buffer.insert_text(&text, overwrite, true)
+                        }
+                        BufferEdit::DeleteBeforeCursor { count } => {
+                            buffer.delete_before_cursor(count);
+                        }
+                        BufferEdit::Delete { count } => {
+                            buffer.delete(count);
+                        }
+                        BufferEdit::CursorLeft { count } => buffer.cursor_left(count),
+                        BufferEdit::CursorRight { count } => buffer.cursor_right(count),
+                        BufferEdit::CursorUp { count } => buffer.cursor_up(count),
+                        BufferEdit::CursorDown { count } => buffer.cursor_down(count),
+                        BufferEdit::SetCursorPosition { position } => {
+                            buffer.set_cursor_position(position)
+                        }
+                    }
+                }
+                0 // Success
+            } else {
+                1 // Error: buffer not found
+            }
+        } else {
+            1 // Error: buffers not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn buffer_to_wasm_state(buffer_id: u32) -> u64 {
     init_buffers();
@@ -590,6 +886,25 @@ pub unsafe extern "C" fn buffer_from_wasm_state(state_ptr: *const u8, state_len:
     }
 }
 
+/// Length of the buffer text in UTF-8 bytes, without copying the text out.
+/// Returns `u32::MAX` when the ID is unknown, which no real length can reach.
+#[no_mangle]
+pub extern "C" fn buffer_text_len(buffer_id: u32) -> u32 {
+    init_buffers();
+
+    unsafe {
+        if let Some(ref buffers) = BUFFERS {
+            if let Some(buffer) = buffers.get(&buffer_id) {
+                buffer.text().len() as u32
+            } else {
+                u32::MAX // Error: buffer not found
+            }
+        } else {
+            u32::MAX // Error: buffers not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn buffer_get_document(buffer_id: u32) -> u32 {
     init_buffers();
@@ -725,6 +1040,55 @@ pub unsafe extern "C" fn document_with_text_and_key(
     id
 }
 
+#[derive(serde::Deserialize)]
+struct DocumentSpec {
+    text: String,
+    #[serde(default)]
+    cursor: usize,
+}
+
+/// Create documents from a JSON array of `{"text", "cursor"}` objects
+///
+/// Returns a JSON array of the new document IDs in input order. The whole
+/// batch is parsed before any document is created, so malformed input
+/// creates nothing.
+///
+/// # Safety
+/// The caller must ensure that `specs_ptr` points to a valid UTF-8 JSON memory region of at least `specs_len` bytes.
+#[no_mangle]
+pub unsafe extern "C" fn documents_with_text(specs_ptr: *const u8, specs_len: u32) -> u64 {
+    init_documents();
+
+    let specs_json = unsafe {
+        let slice = slice::from_raw_parts(specs_ptr, specs_len as usize);
+        match str::from_utf8(slice) {
+            Ok(s) => s,
+            Err(_) => return 0, // Error: invalid UTF-8
+        }
+    };
+
+    let specs = match serde_json::from_str::<Vec<DocumentSpec>>(specs_json) {
+        Ok(specs) => specs,
+        Err(_) => return 0, // Error: invalid specs
+    };
+
+    let mut ids = Vec::with_capacity(specs.len());
+    unsafe {
+        if let Some(ref mut documents) = DOCUMENTS {
+            for spec in specs {
+                let id = NEXT_ID;
+                NEXT_ID += 1;
+                documents.insert(id, Document::with_text(spec.text, spec.cursor));
+                ids.push(id);
+            }
+        } else {
+            return 0; // Error: documents not initialized
+        }
+    }
+
+    serialize_json(&ids)
+}
+
 #[no_mangle]
 pub extern "C" fn document_text_before_cursor(document_id: u32) -> u64 {
     init_documents();
@@ -797,6 +1161,63 @@ pub extern "C" fn document_get_word_after_cursor(document_id: u32) -> u64 {
     }
 }
 
+/// Word under the cursor with its rune range, as returned by document_current_word
+#[derive(serde::Serialize)]
+struct CurrentWord<'a> {
+    word: &'a str,
+    start: usize,
+    end: usize,
+}
+
+#[no_mangle]
+pub extern "C" fn document_current_word(document_id: u32) -> u64 {
+    init_documents();
+
+    unsafe {
+        if let Some(ref documents) = DOCUMENTS {
+            if let Some(document) = documents.get(&document_id) {
+                let (start, end) = document.current_word_range();
+                serialize_json(&CurrentWord {
+                    word: document.current_word(),
+                    start,
+                    end,
+                })
+            } else {
+                0 // Error: document not found
+            }
+        } else {
+            0 // Error: documents not initialized
+        }
+    }
+}
+
+/// Current line split at the cursor, as returned by document_current_line_split
+#[derive(serde::Serialize)]
+struct CurrentLineSplit<'a> {
+    before: &'a str,
+    after: &'a str,
+}
+
+#[no_mangle]
+pub extern "C" fn document_current_line_split(document_id: u32) -> u64 {
+    init_documents();
+
+    unsafe {
+        if let Some(ref documents) = DOCUMENTS {
+            if let Some(document) = documents.get(&document_id) {
+                serialize_json(&CurrentLineSplit {
+                    before: document.current_line_before_cursor(),
+                    after: document.current_line_after_cursor(),
+                })
+            } else {
+                0 // Error: document not found
+            }
+        } else {
+            0 // Error: documents not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn document_current_line(document_id: u32) -> u64 {
     init_documents();
@@ -815,6 +1236,25 @@ pub extern "C" fn document_current_line(document_id: u32) -> u64 {
     }
 }
 
+/// Length of the document text in UTF-8 bytes, without copying the text out.
+/// Returns `u32::MAX` when the ID is unknown, which no real length can reach.
+#[no_mangle]
+pub extern "C" fn document_text_len(document_id: u32) -> u32 {
+    init_documents();
+
+    unsafe {
+        if let Some(ref documents) = DOCUMENTS {
+            if let Some(document) = documents.get(&document_id) {
+                document.text().len() as u32
+            } else {
+                u32::MAX // Error: document not found
+            }
+        } else {
+            u32::MAX // Error: documents not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn document_line_count(document_id: u32) -> u32 {
     init_documents();
@@ -883,6 +1323,23 @@ pub extern "C" fn document_display_cursor_position(document_id: u32) -> u32 {
     }
 }
 
+#[no_mangle]
+pub extern "C" fn document_display_column(document_id: u32) -> u32 {
+    init_documents();
+
+    unsafe {
+        if let Some(ref documents) = DOCUMENTS {
+            if let Some(document) = documents.get(&document_id) {
+                document.display_column() as u32
+            } else {
+                0 // Error: document not found
+            }
+        } else {
+            0 // Error: documents not initialized
+        }
+    }
+}
+
 #[no_mangle]
 pub extern "C" fn document_to_wasm_state(document_id: u32) -> u64 {
     init_documents();
@@ -950,3 +1407,41 @@ pub extern "C" fn destroy_document(document_id: u32) -> u32 {
         }
     }
 }
+
+/// Destroy every document in a JSON array of document IDs
+///
+/// IDs that do not exist are skipped; the result is 1 if any were missing.
+///
+/// # Safety
+/// The caller must ensure that `ids_ptr` points to a valid UTF-8 JSON memory region of at least `ids_len` bytes.
+#[no_mangle]
+pub unsafe extern "C" fn destroy_documents(ids_ptr: *const u8, ids_len: u32) -> u32 {
+    init_documents();
+
+    let ids_json = unsafe {
+        let slice = slice::from_raw_parts(ids_ptr, ids_len as usize);
+        match str::from_utf8(slice) {
+            Ok(s) => s,
+            Err(_) => return 1, // Error: invalid UTF-8
+        }
+    };
+
+    let ids = match serde_json::from_str::<Vec<u32>>(ids_json) {
+        Ok(ids) => ids,
+        Err(_) => return 1, // Error: invalid IDs
+    };
+
+    unsafe {
+        if let Some(ref mut documents) = DOCUMENTS {
+            let mut result = 0;
+            for id in ids {
+                if documents.remove(&id).is_none() {
+                    result = 1; // Error: document not found
+                }
+            }
+            result
+        } else {
+            1 // Error: documents not initialized
+        }
+    }
+}